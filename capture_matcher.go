@@ -0,0 +1,156 @@
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/golang/mock/gomock"
+)
+
+// Capture is a GoMock matcher which saves arguments of mocked calls to check them later, eg:
+//
+//	user := &helpers.Capture{Matcher: helpers.StructMatcher{...}}
+//	repoMock.EXPECT().CreateUser(user).Return(nil)
+//	...
+//	createdUser := user.Last().(*User)
+//
+// The capture should be used by a pointer, it's safe for concurrent use. Only arguments which
+// are accepted by the wrapped Matcher are saved, if the Matcher is nil, any argument is accepted.
+//
+// GoMock runs matchers of expectations before it checks their prerequisites (After, InOrder) and
+// numbers of calls, so arguments of calls which are then rejected by the expectation (and matched
+// by another one or failed) are captured too. Use the capture in expectations of methods which
+// don't have other expectations, or check calls in .Do() if they should be told apart
+type Capture struct {
+	Matcher gomock.Matcher // an optional matcher for the captured arguments
+
+	mu     sync.RWMutex
+	values []interface{}
+}
+
+// String return a description of the wrapped matcher
+func (c *Capture) String() string {
+	if c.Matcher == nil {
+		return "is anything (captured)"
+	}
+	return c.Matcher.String() + " (captured)"
+}
+
+// Matches save the argument if it's accepted by the wrapped matcher, even if GoMock rejects
+// the call later
+func (c *Capture) Matches(x interface{}) bool {
+	if c.Matcher != nil && !c.Matcher.Matches(x) {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = append(c.values, x)
+	return true
+}
+
+// Got implements gomock.GotFormatter using the wrapped matcher if it's possible
+func (c *Capture) Got(x interface{}) string {
+	if formatter, ok := c.Matcher.(gomock.GotFormatter); ok {
+		return formatter.Got(x)
+	}
+	return fmt.Sprintf("%v", x)
+}
+
+// Len return a number of captured arguments
+func (c *Capture) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.values)
+}
+
+// All return a copy of all captured arguments in the order of capturing
+func (c *Capture) All() []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]interface{}{}, c.values...)
+}
+
+// Last return the last captured argument or nil if nothing has been captured
+func (c *Capture) Last() interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.values) == 0 {
+		return nil
+	}
+	return c.values[len(c.values)-1]
+}
+
+// Reset remove all captured arguments
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = nil
+}
+
+// LastInto save the last captured argument into the target pointer, pointers to the argument
+// are dereferenced if the target type requires it. It returns an error if nothing has been
+// captured or the argument cannot be assigned to the target
+func (c *Capture) LastInto(target interface{}) error {
+	if c.Len() == 0 {
+		return fmt.Errorf("nothing has been captured")
+	}
+	return assignTo(c.Last(), target)
+}
+
+// LastString return the last captured argument as a string
+func (c *Capture) LastString() (string, bool) {
+	var s string
+	err := c.LastInto(&s)
+	return s, err == nil
+}
+
+// LastInt64 return the last captured argument of an integer kind as an int64
+func (c *Capture) LastInt64() (int64, bool) {
+	v := reflect.ValueOf(c.Last())
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), true
+	}
+	return 0, false
+}
+
+// LastTime return the last captured argument as a time.Time
+func (c *Capture) LastTime() (time.Time, bool) {
+	var t time.Time
+	err := c.LastInto(&t)
+	return t, err == nil
+}
+
+// assignTo assign the value to the target pointer, pointers to the value are dereferenced
+// and values of convertible types of the same kind are converted
+func assignTo(value interface{}, target interface{}) error {
+	t := reflect.ValueOf(target)
+	if t.Kind() != reflect.Ptr || t.IsNil() {
+		return fmt.Errorf("target should be a non-nil pointer, got %T", target)
+	}
+	t = t.Elem()
+
+	v := reflect.ValueOf(value)
+	for v.IsValid() {
+		if v.Type().AssignableTo(t.Type()) {
+			t.Set(v)
+			return nil
+		}
+		if converted, ok := convertValue(v, t.Type()); ok {
+			t.Set(converted)
+			return nil
+		}
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+	return fmt.Errorf("captured %T cannot be assigned to %s", value, t.Type())
+}
@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Capture(t *testing.T) {
+	Convey("Test Capture", t, func() {
+		Convey("Test empty capture", func() {
+			capture := &Capture{}
+			So(capture.Len(), ShouldEqual, 0)
+			So(capture.Last(), ShouldBeNil)
+			So(capture.All(), ShouldBeEmpty)
+			So(capture.LastInto(new(string)), ShouldNotBeNil)
+			So(capture.String(), ShouldEqual, "is anything (captured)")
+		})
+		Convey("Test capture without a matcher", func() {
+			capture := &Capture{}
+			So(capture.Matches("first"), ShouldBeTrue)
+			So(capture.Matches(2), ShouldBeTrue)
+			So(capture.All(), ShouldResemble, []interface{}{"first", 2})
+			So(capture.Last(), ShouldEqual, 2)
+
+			capture.Reset()
+			So(capture.Len(), ShouldEqual, 0)
+		})
+		Convey("Test capture with a matcher", func() {
+			capture := &Capture{Matcher: StructMatcher{Matching: TestStruct{IntField: 1}, Partial: true}}
+			So(capture.Matches(TestStruct{IntField: 2}), ShouldBeFalse)
+			So(capture.Matches(&TestStruct{IntField: 1}), ShouldBeFalse)
+			So(capture.Matches(TestStruct{IntField: 1, StringField: "captured"}), ShouldBeTrue)
+			So(capture.All(), ShouldResemble, []interface{}{TestStruct{IntField: 1, StringField: "captured"}})
+			So(capture.String(), ShouldStartWith, "StructMatcher to")
+			So(capture.Got(TestStruct{IntField: 2}), ShouldContainSubstring, "IntField [DeepEqual]")
+
+			var captured TestStruct
+			So(capture.LastInto(&captured), ShouldBeNil)
+			So(captured.StringField, ShouldEqual, "captured")
+		})
+		Convey("Test typed accessors", func() {
+			capture := &Capture{}
+			str := "value"
+			capture.Matches(&str)
+			s, ok := capture.LastString()
+			So(ok, ShouldBeTrue)
+			So(s, ShouldEqual, "value")
+			_, ok = capture.LastInt64()
+			So(ok, ShouldBeFalse)
+
+			capture.Matches(uint16(5))
+			i, ok := capture.LastInt64()
+			So(ok, ShouldBeTrue)
+			So(i, ShouldEqual, 5)
+			_, ok = capture.LastTime()
+			So(ok, ShouldBeFalse)
+
+			now := time.Now()
+			capture.Matches(now)
+			tm, ok := capture.LastTime()
+			So(ok, ShouldBeTrue)
+			So(tm, ShouldEqual, now)
+			So(capture.LastInto(tm), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestCaptureConcurrent(t *testing.T) {
+	capture := &Capture{Matcher: gomock.Not(0)}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			capture.Matches(i)
+			capture.Last()
+		}(i)
+	}
+	wg.Wait()
+
+	if capture.Len() != 99 {
+		t.Errorf("expected 99 captured values, got %d", capture.Len())
+	}
+}
+
+// testSaver is a receiver of calls recorded by a gomock.Controller
+type testSaver struct{}
+
+func (testSaver) Save(int) {}
+
+func TestCaptureRejectedCalls(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// GoMock checks arguments of every expectation before their prerequisites and numbers of calls,
+	// so the first call is captured, although it's rejected and matched by the second expectation
+	saver, method := &testSaver{}, reflect.TypeOf(testSaver{}.Save)
+	capture := &Capture{}
+	first := ctrl.RecordCallWithMethodType(saver, "Save", method, capture)
+	second := ctrl.RecordCallWithMethodType(saver, "Save", method, gomock.Any())
+	first.After(second)
+
+	ctrl.Call(saver, "Save", 1)
+	ctrl.Call(saver, "Save", 2)
+	if all := capture.All(); !reflect.DeepEqual(all, []interface{}{1, 2}) {
+		t.Errorf("expected both calls to be captured, got %v", all)
+	}
+}
@@ -0,0 +1,162 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+)
+
+// pathWildcard matches any index of a slice or an array and any key of a map
+const pathWildcard = "*"
+
+// pathStep is a single step of a field path: a struct field name or an index (a map key)
+type pathStep struct {
+	name  string
+	index bool
+
+	// embedded steps may be omitted in patterns, if the next field is promoted through them
+	embedded   bool
+	promoted   bool
+	owner      reflect.Type // a struct which includes the embedded field
+	fieldIndex int          // an index of the embedded field in the owner
+}
+
+// fieldPath is a path to a nested field, e.g. "Owner.CreatedAt", "Items[*].ID" or "Meta[key]"
+type fieldPath []pathStep
+
+// parseFieldPath split a dotted/indexed path into steps
+func parseFieldPath(path string) fieldPath {
+	steps := make(fieldPath, 0, strings.Count(path, ".")+strings.Count(path, "[")+1)
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				end = len(path)
+			}
+			steps = append(steps, pathStep{name: path[1:end], index: true})
+			path = path[Min(end+1, len(path)):]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			steps = append(steps, pathStep{name: path[:end]})
+			path = path[end:]
+		}
+	}
+	return steps
+}
+
+// String return a dotted/indexed presentation of the path
+func (p fieldPath) String() string {
+	var b strings.Builder
+	for i, step := range p {
+		switch {
+		case step.index:
+			b.WriteString("[" + step.name + "]")
+		case i > 0:
+			b.WriteString("." + step.name)
+		default:
+			b.WriteString(step.name)
+		}
+	}
+	return b.String()
+}
+
+// field return a new path to the field of the struct which is located by the path
+func (p fieldPath) field(name string) fieldPath {
+	return p.append(pathStep{name: name})
+}
+
+// index return a new path to the element (or the map value) which is located by the path
+func (p fieldPath) index(key string) fieldPath {
+	return p.append(pathStep{name: key, index: true})
+}
+
+// promotion return the struct which the trailing embedded fields of the path belong to,
+// and an index sequence of these fields, fields of the last one may be promoted to the owner
+func (p fieldPath) promotion() (owner reflect.Type, index []int) {
+	i := len(p)
+	for i > 0 && p[i-1].embedded {
+		i--
+	}
+	if i == len(p) {
+		return nil, nil
+	}
+	index = make([]int, 0, len(p)-i)
+	for _, step := range p[i:] {
+		index = append(index, step.fieldIndex)
+	}
+	return p[i].owner, index
+}
+
+// isPromoted return true if the field of the embedded struct is accessible by its name from the owner
+func isPromoted(owner reflect.Type, index []int, fieldIndex int, name string) bool {
+	field, ok := owner.FieldByName(name)
+	if !ok || len(field.Index) != len(index)+1 || field.Index[len(index)] != fieldIndex {
+		return false
+	}
+	for i := range index {
+		if field.Index[i] != index[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p fieldPath) append(step pathStep) fieldPath {
+	result := make(fieldPath, len(p), len(p)+1)
+	copy(result, p)
+	return append(result, step)
+}
+
+// matches return true if the path is matched by the pattern, embedded fields may be omitted
+// in the pattern when it addresses promoted fields
+func (p fieldPath) matches(pattern fieldPath) bool {
+	if len(p) == 0 {
+		return len(pattern) == 0
+	}
+	if p[0].embedded && len(p) > 1 && p[1].promoted && p[1:].matches(pattern) {
+		return true
+	}
+	if len(pattern) == 0 || !p[0].matchedBy(pattern[0]) {
+		return false
+	}
+	return p[1:].matches(pattern[1:])
+}
+
+// within return true if the pattern points to a field which is nested into the path
+func (p fieldPath) within(pattern fieldPath) bool {
+	for end := len(p); end >= 0; end-- {
+		for i := 0; i < len(pattern); i++ {
+			if p[:end].matches(pattern[:i]) {
+				return true
+			}
+		}
+		// trailing embedded fields may be omitted, their fields are checked after descending
+		if end == 0 || !p[end-1].embedded {
+			break
+		}
+	}
+	return false
+}
+
+// hasWildcard return true if the path includes wildcard indexes
+func (p fieldPath) hasWildcard() bool {
+	for _, step := range p {
+		if step.index && step.name == pathWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// matchedBy return true if the step is matched by the pattern step
+func (s pathStep) matchedBy(pattern pathStep) bool {
+	if s.index != pattern.index {
+		return false
+	}
+	return s.name == pattern.name || s.index && pattern.name == pathWildcard
+}
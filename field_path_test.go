@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	cases := []struct {
+		path  string
+		steps fieldPath
+	}{
+		{"", fieldPath{}},
+		{"Key", fieldPath{{name: "Key"}}},
+		{"Owner.CreatedAt", fieldPath{{name: "Owner"}, {name: "CreatedAt"}}},
+		{"Items[*].ID", fieldPath{{name: "Items"}, {name: "*", index: true}, {name: "ID"}}},
+		{"Meta[key]", fieldPath{{name: "Meta"}, {name: "key", index: true}}},
+		{"Matrix[1][2]", fieldPath{{name: "Matrix"}, {name: "1", index: true}, {name: "2", index: true}}},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.path, func(t *testing.T) {
+			steps := parseFieldPath(tst.path)
+			if !steps.matches(tst.steps) || len(steps) != len(tst.steps) {
+				t.Errorf("expected %v, got %v", tst.steps, steps)
+			}
+			if steps.String() != tst.path {
+				t.Errorf("expected '%s', got '%s'", tst.path, steps.String())
+			}
+		})
+	}
+}
+
+func TestFieldPathMatches(t *testing.T) {
+	cases := []struct {
+		path    string
+		pattern string
+		matches bool
+		within  bool
+	}{
+		{"Owner.CreatedAt", "Owner.CreatedAt", true, false},
+		{"Owner", "Owner.CreatedAt", false, true},
+		{"Items[3].ID", "Items[*].ID", true, false},
+		{"Items[3]", "Items[*].ID", false, true},
+		{"Items[3].ID", "Items[2].ID", false, false},
+		{"Meta[key]", "Meta[key]", true, false},
+		{"Meta.key", "Meta[key]", false, false},
+		{"Items", "Owner.CreatedAt", false, false},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.path+" by "+tst.pattern, func(t *testing.T) {
+			path, pattern := parseFieldPath(tst.path), parseFieldPath(tst.pattern)
+			if path.matches(pattern) != tst.matches {
+				t.Errorf("expected matches to be %t", tst.matches)
+			}
+			if path.within(pattern) != tst.within {
+				t.Errorf("expected within to be %t", tst.within)
+			}
+		})
+	}
+}
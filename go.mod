@@ -1,13 +1,18 @@
 module github.com/astota/go-helperz
 
-go 1.12
+go 1.18
 
 require (
 	github.com/go-pg/pg v6.15.1+incompatible
-	github.com/golang/mock v1.2.0
+	github.com/golang/mock v1.6.0
 	github.com/google/uuid v1.1.1
-	github.com/gopherjs/gopherjs v0.0.0-20190309154008-847fc94819f9
-	github.com/jtolds/gls v4.20.0+incompatible
+	github.com/jackc/pgtype v1.14.0
 	github.com/smartystreets/assertions v0.0.0-20180927180507-b2de0cb4f26d
 	github.com/smartystreets/goconvey v0.0.0-20190306220146-200a235640ff
 )
+
+require (
+	github.com/gopherjs/gopherjs v0.0.0-20190309154008-847fc94819f9 // indirect
+	github.com/jackc/pgio v1.0.0 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+)
@@ -0,0 +1,517 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+)
+
+// RegexMatcher checks that a string value matches the regular expression
+type RegexMatcher struct {
+	Matching string
+}
+
+// String return a description of the matcher
+func (m RegexMatcher) String() string {
+	return fmt.Sprintf("matches regexp %q", m.Matching)
+}
+
+// Matches return true if the string value matches the regular expression
+func (m RegexMatcher) Matches(x interface{}) bool {
+	s, ok := toString(x)
+	if !ok {
+		return false
+	}
+	matched, err := regexp.MatchString(m.Matching, s)
+	return err == nil && matched
+}
+
+// PrefixMatcher checks that a string value starts with the prefix
+type PrefixMatcher struct {
+	Matching string
+}
+
+// String return a description of the matcher
+func (m PrefixMatcher) String() string {
+	return fmt.Sprintf("has prefix %q", m.Matching)
+}
+
+// Matches return true if the string value starts with the prefix
+func (m PrefixMatcher) Matches(x interface{}) bool {
+	s, ok := toString(x)
+	return ok && strings.HasPrefix(s, m.Matching)
+}
+
+// SuffixMatcher checks that a string value ends with the suffix
+type SuffixMatcher struct {
+	Matching string
+}
+
+// String return a description of the matcher
+func (m SuffixMatcher) String() string {
+	return fmt.Sprintf("has suffix %q", m.Matching)
+}
+
+// Matches return true if the string value ends with the suffix
+func (m SuffixMatcher) Matches(x interface{}) bool {
+	s, ok := toString(x)
+	return ok && strings.HasSuffix(s, m.Matching)
+}
+
+// SubstringMatcher checks that a string value contains the substring
+type SubstringMatcher struct {
+	Matching string
+}
+
+// String return a description of the matcher
+func (m SubstringMatcher) String() string {
+	return fmt.Sprintf("contains %q", m.Matching)
+}
+
+// Matches return true if the string value contains the substring
+func (m SubstringMatcher) Matches(x interface{}) bool {
+	s, ok := toString(x)
+	return ok && strings.Contains(s, m.Matching)
+}
+
+// RangeMatcher checks that a numeric value is in the range [Min, Max], nil bounds are not checked
+type RangeMatcher struct {
+	Min interface{}
+	Max interface{}
+}
+
+// String return a description of the matcher
+func (m RangeMatcher) String() string {
+	return fmt.Sprintf("is in range [%v, %v]", m.Min, m.Max)
+}
+
+// Matches return true if the numeric value is in the range
+func (m RangeMatcher) Matches(x interface{}) bool {
+	value, ok := toFloat(x)
+	if !ok {
+		return false
+	}
+	if m.Min != nil {
+		min, ok := toFloat(m.Min)
+		if !ok || value < min {
+			return false
+		}
+	}
+	if m.Max != nil {
+		max, ok := toFloat(m.Max)
+		if !ok || value > max {
+			return false
+		}
+	}
+	return true
+}
+
+// ApproxMatcher checks that a numeric value differs from Matching not more than by Epsilon
+type ApproxMatcher struct {
+	Matching float64
+	Epsilon  float64
+}
+
+// String return a description of the matcher
+func (m ApproxMatcher) String() string {
+	return fmt.Sprintf("is approximately equal to %v (±%v)", m.Matching, m.Epsilon)
+}
+
+// Matches return true if the numeric value is approximately equal to Matching
+func (m ApproxMatcher) Matches(x interface{}) bool {
+	value, ok := toFloat(x)
+	return ok && math.Abs(value-m.Matching) <= m.Epsilon
+}
+
+// LenMatcher checks the length of a string, a slice, an array, a map or a channel
+type LenMatcher struct {
+	Matching int
+}
+
+// String return a description of the matcher
+func (m LenMatcher) String() string {
+	return fmt.Sprintf("has length %d", m.Matching)
+}
+
+// Matches return true if the value has the expected length
+func (m LenMatcher) Matches(x interface{}) bool {
+	length, ok := toLen(x)
+	return ok && length == m.Matching
+}
+
+// EmptyMatcher checks that a value is nil or has zero length
+type EmptyMatcher struct{}
+
+// String return a description of the matcher
+func (m EmptyMatcher) String() string {
+	return "is empty"
+}
+
+// Matches return true if the value is nil or has zero length
+func (m EmptyMatcher) Matches(x interface{}) bool {
+	if x == nil {
+		return true
+	}
+	v := reflect.ValueOf(x)
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return true
+	}
+	length, ok := toLen(x)
+	return ok && length == 0
+}
+
+// ContainsAllMatcher checks that a slice or an array contains all elements of Matching,
+// elements of Matching may be GoMock matchers
+type ContainsAllMatcher struct {
+	Matching interface{}
+}
+
+// String return a description of the matcher
+func (m ContainsAllMatcher) String() string {
+	return fmt.Sprintf("contains all of %v", m.Matching)
+}
+
+// Matches return true if the slice contains all expected elements
+func (m ContainsAllMatcher) Matches(x interface{}) bool {
+	expected, actual, ok := toElements(m.Matching, x)
+	if !ok {
+		return false
+	}
+	for _, matcher := range expected {
+		if !containsMatch(matcher, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAnyMatcher checks that a slice or an array contains at least one element of Matching,
+// elements of Matching may be GoMock matchers
+type ContainsAnyMatcher struct {
+	Matching interface{}
+}
+
+// String return a description of the matcher
+func (m ContainsAnyMatcher) String() string {
+	return fmt.Sprintf("contains any of %v", m.Matching)
+}
+
+// Matches return true if the slice contains at least one expected element
+func (m ContainsAnyMatcher) Matches(x interface{}) bool {
+	expected, actual, ok := toElements(m.Matching, x)
+	if !ok {
+		return false
+	}
+	for _, matcher := range expected {
+		if containsMatch(matcher, actual) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnorderedMatcher checks that a slice or an array has the same elements as Matching in any order,
+// elements of Matching may be GoMock matchers
+type UnorderedMatcher struct {
+	Matching interface{}
+}
+
+// String return a description of the matcher
+func (m UnorderedMatcher) String() string {
+	return fmt.Sprintf("has the same elements as %v in any order", m.Matching)
+}
+
+// Matches return true if every element of the slice matches exactly one expected element
+func (m UnorderedMatcher) Matches(x interface{}) bool {
+	expected, actual, ok := toElements(m.Matching, x)
+	if !ok || len(expected) != len(actual) {
+		return false
+	}
+	return matchUnordered(expected, actual, make([]bool, len(actual)))
+}
+
+// HasKeysMatcher checks that a map has all keys from Matching
+type HasKeysMatcher struct {
+	Matching []interface{}
+}
+
+// String return a description of the matcher
+func (m HasKeysMatcher) String() string {
+	return fmt.Sprintf("has keys %v", m.Matching)
+}
+
+// Matches return true if the map includes all expected keys
+func (m HasKeysMatcher) Matches(x interface{}) bool {
+	v := reflect.ValueOf(x)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Map {
+		return false
+	}
+	for _, key := range m.Matching {
+		if key == nil || !mapIndex(v, reflect.ValueOf(key)).IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+// JSONMatcher checks that a JSON document is semantically equal to Matching, whitespaces and
+// an order of object keys are ignored. Strings, byte slices and json.RawMessage are treated
+// as JSON documents, other values are marshaled to JSON before comparison
+type JSONMatcher struct {
+	Matching interface{}
+}
+
+// String return a description of the matcher
+func (m JSONMatcher) String() string {
+	if data, err := toJSON(m.Matching); err == nil {
+		return fmt.Sprintf("is JSON equal to %s", data)
+	}
+	return fmt.Sprintf("is JSON equal to %v", m.Matching)
+}
+
+// Matches return true if both JSON documents have the same data
+func (m JSONMatcher) Matches(x interface{}) bool {
+	expected, err := decodeJSON(m.Matching)
+	if err != nil {
+		return false
+	}
+	actual, err := decodeJSON(x)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+// AllOf return a matcher which matches if all the matchers match, raw values are compared via gomock.Eq
+func AllOf(matchers ...interface{}) gomock.Matcher {
+	return allOfMatcher{matchers: toMatchers(matchers)}
+}
+
+type allOfMatcher struct {
+	matchers []gomock.Matcher
+}
+
+func (m allOfMatcher) String() string {
+	return "all of " + joinMatchers(m.matchers, "; ")
+}
+
+func (m allOfMatcher) Matches(x interface{}) bool {
+	for _, matcher := range m.matchers {
+		if !matcher.Matches(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// AnyOf return a matcher which matches if at least one of the matchers matches, raw values are compared via gomock.Eq
+func AnyOf(matchers ...interface{}) gomock.Matcher {
+	return anyOfMatcher{matchers: toMatchers(matchers)}
+}
+
+type anyOfMatcher struct {
+	matchers []gomock.Matcher
+}
+
+func (m anyOfMatcher) String() string {
+	return "any of " + joinMatchers(m.matchers, "; ")
+}
+
+func (m anyOfMatcher) Matches(x interface{}) bool {
+	for _, matcher := range m.matchers {
+		if matcher.Matches(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// Not return a matcher which inverts the matcher, a raw value is compared via gomock.Eq
+func Not(matcher interface{}) gomock.Matcher {
+	return notMatcher{matcher: toMatcher(matcher)}
+}
+
+type notMatcher struct {
+	matcher gomock.Matcher
+}
+
+func (m notMatcher) String() string {
+	return "not(" + m.matcher.String() + ")"
+}
+
+func (m notMatcher) Matches(x interface{}) bool {
+	return !m.matcher.Matches(x)
+}
+
+// Func return a matcher which checks values by the predicate, the description is used in failure messages
+func Func(description string, predicate func(x interface{}) bool) gomock.Matcher {
+	return funcMatcher{description: description, predicate: predicate}
+}
+
+type funcMatcher struct {
+	description string
+	predicate   func(x interface{}) bool
+}
+
+func (m funcMatcher) String() string {
+	return m.description
+}
+
+func (m funcMatcher) Matches(x interface{}) bool {
+	return m.predicate(x)
+}
+
+// toMatcher return the value itself if it's a matcher or gomock.Eq matcher for it
+func toMatcher(value interface{}) gomock.Matcher {
+	if matcher, ok := value.(gomock.Matcher); ok {
+		return matcher
+	}
+	return gomock.Eq(value)
+}
+
+func toMatchers(values []interface{}) []gomock.Matcher {
+	matchers := make([]gomock.Matcher, 0, len(values))
+	for _, value := range values {
+		matchers = append(matchers, toMatcher(value))
+	}
+	return matchers
+}
+
+func joinMatchers(matchers []gomock.Matcher, sep string) string {
+	descriptions := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		descriptions = append(descriptions, matcher.String())
+	}
+	return strings.Join(descriptions, sep)
+}
+
+// toString return a value of a string kind, a byte slice or a pointer to them as a string
+func toString(x interface{}) (string, bool) {
+	v := reflect.ValueOf(x)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch {
+	case v.Kind() == reflect.String:
+		return v.String(), true
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		return string(v.Bytes()), true
+	}
+	return "", false
+}
+
+// toFloat return a numeric value or a pointer to it as a float64
+func toFloat(x interface{}) (float64, bool) {
+	v := reflect.ValueOf(x)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// toLen return the length of a string, a slice, an array, a map, a channel or a pointer to them
+func toLen(x interface{}) (int, bool) {
+	v := reflect.ValueOf(x)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// toElements return matchers of the expected elements and the actual elements of slices or arrays
+func toElements(expected, actual interface{}) ([]gomock.Matcher, []interface{}, bool) {
+	e := reflect.ValueOf(expected)
+	a := reflect.ValueOf(actual)
+	for a.Kind() == reflect.Ptr && !a.IsNil() {
+		a = a.Elem()
+	}
+	if e.Kind() != reflect.Slice && e.Kind() != reflect.Array || a.Kind() != reflect.Slice && a.Kind() != reflect.Array {
+		return nil, nil, false
+	}
+	matchers := make([]gomock.Matcher, 0, e.Len())
+	for i := 0; i < e.Len(); i++ {
+		matchers = append(matchers, toMatcher(e.Index(i).Interface()))
+	}
+	elements := make([]interface{}, 0, a.Len())
+	for i := 0; i < a.Len(); i++ {
+		elements = append(elements, a.Index(i).Interface())
+	}
+	return matchers, elements, true
+}
+
+// containsMatch return true if at least one of the elements matches
+func containsMatch(matcher gomock.Matcher, elements []interface{}) bool {
+	for _, element := range elements {
+		if matcher.Matches(element) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchUnordered try to assign a distinct actual element to every matcher, the backtracking
+// is needed because matchers may overlap (e.g. gomock.Any() and a certain value)
+func matchUnordered(matchers []gomock.Matcher, elements []interface{}, used []bool) bool {
+	if len(matchers) == 0 {
+		return true
+	}
+	for i, element := range elements {
+		if used[i] || !matchers[0].Matches(element) {
+			continue
+		}
+		used[i] = true
+		if matchUnordered(matchers[1:], elements, used) {
+			return true
+		}
+		used[i] = false
+	}
+	return false
+}
+
+// toJSON return a JSON document, strings and byte slices are considered as documents already
+func toJSON(x interface{}) ([]byte, error) {
+	switch value := x.(type) {
+	case string:
+		return []byte(value), nil
+	case *string:
+		if value != nil {
+			return []byte(*value), nil
+		}
+	case []byte:
+		return value, nil
+	case json.RawMessage:
+		return value, nil
+	}
+	return json.Marshal(x)
+}
+
+// decodeJSON decode a JSON document into generic maps, slices and values
+func decodeJSON(x interface{}) (interface{}, error) {
+	data, err := toJSON(x)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+type testStringType string
+
+func TestMatchers(t *testing.T) {
+	str := "value"
+	var nilPointer *string
+
+	cases := []struct {
+		name    string
+		matcher gomock.Matcher
+		value   interface{}
+		matches bool
+	}{
+		{"regex", RegexMatcher{Matching: `^usr_\d+$`}, "usr_123", true},
+		{"regex mismatch", RegexMatcher{Matching: `^usr_\d+$`}, "ord_123", false},
+		{"regex invalid", RegexMatcher{Matching: `(`}, "(", false},
+		{"regex not a string", RegexMatcher{Matching: `.*`}, 1, false},
+		{"prefix", PrefixMatcher{Matching: "va"}, &str, true},
+		{"prefix named type", PrefixMatcher{Matching: "va"}, testStringType("value"), true},
+		{"prefix mismatch", PrefixMatcher{Matching: "lue"}, str, false},
+		{"suffix", SuffixMatcher{Matching: "lue"}, []byte(str), true},
+		{"suffix mismatch", SuffixMatcher{Matching: "va"}, str, false},
+		{"substring", SubstringMatcher{Matching: "alu"}, str, true},
+		{"substring mismatch", SubstringMatcher{Matching: "xyz"}, str, false},
+		{"range", RangeMatcher{Min: 1, Max: 10}, int64(10), true},
+		{"range open", RangeMatcher{Min: 1.5}, uint8(200), true},
+		{"range below", RangeMatcher{Min: 1, Max: 10}, 0.5, false},
+		{"range above", RangeMatcher{Max: int32(10)}, 11, false},
+		{"range not a number", RangeMatcher{Min: 1}, "2", false},
+		{"approx", ApproxMatcher{Matching: 0.3, Epsilon: 1e-9}, 0.1 + 0.2, true},
+		{"approx mismatch", ApproxMatcher{Matching: 0.3, Epsilon: 1e-9}, 0.31, false},
+		{"len", LenMatcher{Matching: 2}, map[string]int{"a": 1, "b": 2}, true},
+		{"len string", LenMatcher{Matching: 5}, str, true},
+		{"len mismatch", LenMatcher{Matching: 2}, []int{1}, false},
+		{"len not a collection", LenMatcher{Matching: 0}, 0, false},
+		{"empty nil", EmptyMatcher{}, nil, true},
+		{"empty nil pointer", EmptyMatcher{}, nilPointer, true},
+		{"empty slice", EmptyMatcher{}, []int{}, true},
+		{"empty mismatch", EmptyMatcher{}, str, false},
+		{"contains all", ContainsAllMatcher{Matching: []interface{}{1, gomock.Not(1)}}, []int{3, 2, 1}, true},
+		{"contains all mismatch", ContainsAllMatcher{Matching: []int{1, 4}}, []int{3, 2, 1}, false},
+		{"contains all not a slice", ContainsAllMatcher{Matching: []int{1}}, 1, false},
+		{"contains any", ContainsAnyMatcher{Matching: []string{"a", "b"}}, []string{"c", "b"}, true},
+		{"contains any mismatch", ContainsAnyMatcher{Matching: []string{"a", "b"}}, [2]string{"c", "d"}, false},
+		{"unordered", UnorderedMatcher{Matching: []int{1, 2, 2}}, []int{2, 1, 2}, true},
+		{"unordered overlapped matchers", UnorderedMatcher{Matching: []interface{}{gomock.Any(), 1}}, []int{1, 2}, true},
+		{"unordered duplicates", UnorderedMatcher{Matching: []int{1, 2, 2}}, []int{2, 1, 1}, false},
+		{"unordered length", UnorderedMatcher{Matching: []int{1, 2}}, []int{2, 1, 1}, false},
+		{"has keys", HasKeysMatcher{Matching: []interface{}{"a", "b"}}, map[string]int{"a": 1, "b": 2, "c": 3}, true},
+		{"has keys converted", HasKeysMatcher{Matching: []interface{}{1}}, map[int64]int{1: 1}, true},
+		{"has keys mismatch", HasKeysMatcher{Matching: []interface{}{"a", "d"}}, map[string]int{"a": 1}, false},
+		{"has keys not a map", HasKeysMatcher{Matching: []interface{}{"a"}}, []string{"a"}, false},
+		{"json", JSONMatcher{Matching: `{"a": 1, "b": [1, 2]}`}, []byte(`{"b":[1,2],"a":1.0}`), true},
+		{"json struct", JSONMatcher{Matching: struct {
+			A int `json:"a"`
+		}{A: 1}}, json.RawMessage(`{ "a" : 1 }`), true},
+		{"json mismatch", JSONMatcher{Matching: `{"a": 1}`}, `{"a": 2}`, false},
+		{"json array order", JSONMatcher{Matching: `[1, 2]`}, `[2, 1]`, false},
+		{"json invalid", JSONMatcher{Matching: `{"a": 1}`}, `{"a": 1`, false},
+		{"all of", AllOf(PrefixMatcher{Matching: "va"}, SuffixMatcher{Matching: "ue"}), str, true},
+		{"all of mismatch", AllOf(PrefixMatcher{Matching: "va"}, "another value"), str, false},
+		{"any of", AnyOf("another value", str), str, true},
+		{"any of mismatch", AnyOf(1, 2), 3, false},
+		{"not", Not(PrefixMatcher{Matching: "a"}), str, true},
+		{"not mismatch", Not(str), str, false},
+		{"func", Func("is even", func(x interface{}) bool { return x.(int)%2 == 0 }), 2, true},
+		{"func mismatch", Func("is even", func(x interface{}) bool { return x.(int)%2 == 0 }), 3, false},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			if tst.matcher.Matches(tst.value) != tst.matches {
+				t.Errorf("expected %t for '%v' %v", tst.matches, tst.value, tst.matcher)
+			}
+		})
+	}
+}
+
+func TestMatchersString(t *testing.T) {
+	cases := []struct {
+		matcher gomock.Matcher
+		str     string
+	}{
+		{RegexMatcher{Matching: "^a"}, `matches regexp "^a"`},
+		{RangeMatcher{Min: 1}, "is in range [1, <nil>]"},
+		{JSONMatcher{Matching: map[string]int{"a": 1}}, `is JSON equal to {"a":1}`},
+		{AllOf(LenMatcher{Matching: 1}, EmptyMatcher{}), "all of has length 1; is empty"},
+		{Not(AnyOf(1)), "not(any of is equal to 1 (int))"},
+		{Func("is even", nil), "is even"},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.str, func(t *testing.T) {
+			if tst.matcher.String() != tst.str {
+				t.Errorf("expected '%s', got '%s'", tst.str, tst.matcher.String())
+			}
+		})
+	}
+}
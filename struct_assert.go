@@ -0,0 +1,165 @@
+package helpers
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+)
+
+// TestingT is a part of testing.T which is used by assertions, it's compatible with
+// testify's assert.TestingT, so assertions may be used in testify-based tests as well
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// MatchOption configure a StructMatcher which is used by assertions, options apply the same
+// settings as the corresponding methods of StructMatcherBuilder
+type MatchOption func(*StructMatcherBuilder)
+
+// WithSkipFields make the assertion ignore the fields
+func WithSkipFields(fields ...string) MatchOption {
+	return func(b *StructMatcherBuilder) { b.Skip(fields...) }
+}
+
+// WithMatcherField check the field via a GoMock matcher, raw values are compared via gomock.Eq
+func WithMatcherField(field string, matcher interface{}) MatchOption {
+	return func(b *StructMatcherBuilder) { b.Field(field, matcher) }
+}
+
+// WithSkipUnexported make the assertion ignore unexported fields
+func WithSkipUnexported() MatchOption {
+	return func(b *StructMatcherBuilder) { b.SkipUnexported() }
+}
+
+// WithPartial make the assertion ignore zero fields of the expected structure
+func WithPartial() MatchOption {
+	return func(b *StructMatcherBuilder) { b.Partial() }
+}
+
+// WithTagKey make the assertion address fields by names from the struct tag
+func WithTagKey(key string) MatchOption {
+	return func(b *StructMatcherBuilder) { b.TagKey(key) }
+}
+
+// WithCrossType make the assertion compare structures of different types by field names
+func WithCrossType() MatchOption {
+	return func(b *StructMatcherBuilder) { b.CrossType() }
+}
+
+// WithGolden make the assertion load the expected structure from the golden file
+func WithGolden(name string) MatchOption {
+	return func(b *StructMatcherBuilder) { b.Golden(name) }
+}
+
+// WithNilEqualsEmpty make nil and empty slices and maps equal
+func WithNilEqualsEmpty() MatchOption {
+	return func(b *StructMatcherBuilder) { b.NilEqualsEmpty() }
+}
+
+// WithFloatEpsilon make floats equal if they differ not more than by epsilon
+func WithFloatEpsilon(epsilon float64) MatchOption {
+	return func(b *StructMatcherBuilder) { b.FloatEpsilon(epsilon) }
+}
+
+// WithNaNEqual make NaN floats equal to each other
+func WithNaNEqual() MatchOption {
+	return func(b *StructMatcherBuilder) { b.NaNEqual() }
+}
+
+// WithUnorderedSlices make the assertion match elements of slices and arrays in any order
+func WithUnorderedSlices() MatchOption {
+	return func(b *StructMatcherBuilder) { b.UnorderedSlices() }
+}
+
+// WithComparer set an equality function for values of the same type as the example
+func WithComparer(example interface{}, comparer Comparer) MatchOption {
+	return func(b *StructMatcherBuilder) { b.Comparer(example, comparer) }
+}
+
+// NewStructMatcher return a StructMatcher for the expected structure configured by the options
+func NewStructMatcher(expected interface{}, opts ...MatchOption) StructMatcher {
+	return MatchStruct(expected).With(opts...).matcher
+}
+
+// AssertStructMatches check the actual structure by StructMatcher rules, a failed assertion
+// prints every mismatched field, eg:
+//
+//	helpers.AssertStructMatches(t, expectedApp, app,
+//		helpers.WithSkipFields("ID"),
+//		helpers.WithMatcherField("CreatedAt", helpers.TimeMatcher{now}))
+func AssertStructMatches(t TestingT, expected, actual interface{}, opts ...MatchOption) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if message := matchStruct(actual, expected, opts); message != "" {
+		t.Errorf("%s", message)
+		return false
+	}
+	return true
+}
+
+// AssertMatches check the actual value by a GoMock matcher, e.g. TimeMatcher
+func AssertMatches(t TestingT, matcher gomock.Matcher, actual interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if message := match(matcher, actual); message != "" {
+		t.Errorf("%s", message)
+		return false
+	}
+	return true
+}
+
+// ShouldMatchStruct is a goconvey assertion which checks the actual structure by StructMatcher
+// rules, the expected structure may be followed by options, eg:
+//
+//	So(app, helpers.ShouldMatchStruct, expectedApp, helpers.WithSkipFields("ID"))
+func ShouldMatchStruct(actual interface{}, expected ...interface{}) string {
+	if len(expected) == 0 {
+		return "This assertion requires an expected structure (you provided none)."
+	}
+	opts := make([]MatchOption, 0, len(expected)-1)
+	for _, value := range expected[1:] {
+		opt, ok := value.(MatchOption)
+		if !ok {
+			return fmt.Sprintf("This assertion accepts only MatchOption after the expected structure (you provided %T).", value)
+		}
+		opts = append(opts, opt)
+	}
+	return matchStruct(actual, expected[0], opts)
+}
+
+// ShouldMatch is a goconvey assertion which checks the actual value by a GoMock matcher, eg:
+//
+//	So(app.CreatedAt, helpers.ShouldMatch, helpers.TimeMatcher{now})
+func ShouldMatch(actual interface{}, expected ...interface{}) string {
+	if len(expected) != 1 {
+		return fmt.Sprintf("This assertion requires exactly 1 matcher (you provided %d).", len(expected))
+	}
+	matcher, ok := expected[0].(gomock.Matcher)
+	if !ok {
+		return fmt.Sprintf("This assertion requires a gomock.Matcher (you provided %T).", expected[0])
+	}
+	return match(matcher, actual)
+}
+
+// matchStruct return a failure message if the actual structure does not match the expected one
+func matchStruct(actual, expected interface{}, opts []MatchOption) string {
+	sm, err := MatchStruct(expected).With(opts...).build()
+	if err != nil {
+		return err.Error()
+	}
+	return match(sm, actual)
+}
+
+// match return a failure message with the matcher and the actual value, it's empty if the value matches
+func match(matcher gomock.Matcher, actual interface{}) string {
+	if matcher.Matches(actual) {
+		return ""
+	}
+	got := fmt.Sprintf("%v", actual)
+	if formatter, ok := matcher.(gomock.GotFormatter); ok {
+		got = formatter.Got(actual)
+	}
+	return fmt.Sprintf("Expected: %s\nActual:   %s", matcher, got)
+}
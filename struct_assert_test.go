@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_AssertStructMatches(t *testing.T) {
+	Convey("Test StructMatcher assertions", t, func() {
+		now := time.Now()
+		expected := TestNestedItem{ID: 1, CreatedAt: now}
+		actual := TestNestedItem{ID: 2, CreatedAt: now.Add(time.Second)}
+
+		Convey("Test stdlib assertions", func() {
+			helper := &testHelper{}
+			So(AssertStructMatches(helper, expected, expected), ShouldBeTrue)
+			So(helper.message, ShouldBeEmpty)
+			So(AssertStructMatches(helper, expected, actual,
+				WithSkipFields("ID"),
+				WithMatcherField("CreatedAt", TimeMatcher{now}),
+			), ShouldBeTrue)
+			So(helper.message, ShouldBeEmpty)
+
+			So(AssertStructMatches(helper, expected, actual, WithSkipFields("CreatedAt")), ShouldBeFalse)
+			So(helper.message, ShouldStartWith, "Expected: StructMatcher to ")
+			So(helper.message, ShouldEndWith, "mismatched fields:\n\tID [DeepEqual]: expected 1, got 2")
+
+			So(AssertStructMatches(helper, expected, actual, WithSkipFields("Unknown")), ShouldBeFalse)
+			So(helper.message, ShouldEqual, `StructMatcher: helpers.TestNestedItem has no fields "Unknown"`)
+		})
+		Convey("Test matcher assertions", func() {
+			helper := &testHelper{}
+			So(AssertMatches(helper, TimeMatcher{now}, actual.CreatedAt), ShouldBeTrue)
+			So(helper.message, ShouldBeEmpty)
+			So(AssertMatches(helper, PrefixMatcher{"app_"}, "user_1"), ShouldBeFalse)
+			So(helper.message, ShouldEqual, "Expected: has prefix \"app_\"\nActual:   user_1")
+		})
+		Convey("Test goconvey assertions", func() {
+			So(actual, ShouldMatchStruct, expected, WithSkipFields("ID"), WithMatcherField("CreatedAt", TimeMatcher{now}))
+			So(actual.CreatedAt, ShouldMatch, TimeMatcher{now})
+
+			message := ShouldMatchStruct(actual, expected, WithSkipFields("ID"))
+			So(strings.Contains(message, "CreatedAt [DeepEqual]"), ShouldBeTrue)
+			So(ShouldMatchStruct(actual), ShouldNotBeEmpty)
+			So(ShouldMatchStruct(actual, expected, "ID"), ShouldNotBeEmpty)
+			So(ShouldMatch(actual), ShouldNotBeEmpty)
+			So(ShouldMatch(actual, expected), ShouldNotBeEmpty)
+			So(ShouldMatch(1, RangeMatcher{Min: 2}), ShouldNotBeEmpty)
+		})
+		Convey("Test options apply builder settings", func() {
+			sm := NewStructMatcher(expected,
+				WithSkipFields("ID"), WithMatcherField("CreatedAt", TimeMatcher{now}), WithSkipUnexported(),
+				WithPartial(), WithTagKey("json"), WithCrossType(), WithGolden("struct_matcher"),
+				WithNilEqualsEmpty(), WithFloatEpsilon(0.1), WithNaNEqual(), WithUnorderedSlices(),
+			)
+			So(sm, ShouldResemble, MatchStruct(expected).
+				Skip("ID").Field("CreatedAt", TimeMatcher{now}).SkipUnexported().
+				Partial().TagKey("json").CrossType().Golden("struct_matcher").
+				NilEqualsEmpty().FloatEpsilon(0.1).NaNEqual().UnorderedSlices().
+				Build())
+
+			user := TestGoldenUser{
+				ID:        1,
+				Name:      "user",
+				Tags:      []string{"admin", "owner"},
+				CreatedAt: time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC),
+			}
+			So(user, ShouldMatchStruct, nil, WithGolden("struct_matcher"))
+		})
+	})
+}
@@ -0,0 +1,228 @@
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+)
+
+// StructMatcherBuilder is a fluent builder of StructMatcher which checks that all named fields
+// exist in the type of the matching structure, eg:
+//
+//	matcher := helpers.MatchStruct(app).
+//		Skip("Key", "PasswordDigest").
+//		Field("CreatedAt", helpers.TimeMatcher{now}).
+//		Build()
+type StructMatcherBuilder struct {
+	matcher StructMatcher
+}
+
+// MatchStruct start building of a StructMatcher for the matching structure
+func MatchStruct(matching interface{}) *StructMatcherBuilder {
+	return &StructMatcherBuilder{matcher: StructMatcher{Matching: matching}}
+}
+
+// Skip add fields which will be ignored
+func (b *StructMatcherBuilder) Skip(fields ...string) *StructMatcherBuilder {
+	b.matcher.SkipFields = append(b.matcher.SkipFields, fields...)
+	return b
+}
+
+// Field set a GoMock matcher for the field, raw values are compared via gomock.Eq
+func (b *StructMatcherBuilder) Field(field string, matcher interface{}) *StructMatcherBuilder {
+	if b.matcher.MatcherFields == nil {
+		b.matcher.MatcherFields = make(map[string]gomock.Matcher)
+	}
+	b.matcher.MatcherFields[field] = toMatcher(matcher)
+	return b
+}
+
+// SkipUnexported make the matcher ignore unexported fields
+func (b *StructMatcherBuilder) SkipUnexported() *StructMatcherBuilder {
+	b.matcher.SkipUnexported = true
+	return b
+}
+
+// Partial make the matcher ignore zero fields of the matching structure
+func (b *StructMatcherBuilder) Partial() *StructMatcherBuilder {
+	b.matcher.Partial = true
+	return b
+}
+
+// TagKey make the matcher address fields by names from the struct tag
+func (b *StructMatcherBuilder) TagKey(key string) *StructMatcherBuilder {
+	b.matcher.TagKey = key
+	return b
+}
+
+// CrossType make the matcher compare structures of different types by field names
+func (b *StructMatcherBuilder) CrossType() *StructMatcherBuilder {
+	b.matcher.CrossType = true
+	return b
+}
+
+// Golden make the matcher load the expected structure from the golden file
+func (b *StructMatcherBuilder) Golden(name string) *StructMatcherBuilder {
+	b.matcher.Golden = name
+	return b
+}
+
+// NilEqualsEmpty make nil and empty slices and maps equal
+func (b *StructMatcherBuilder) NilEqualsEmpty() *StructMatcherBuilder {
+	b.matcher.NilEqualsEmpty = true
+	return b
+}
+
+// FloatEpsilon make floats equal if they differ not more than by epsilon
+func (b *StructMatcherBuilder) FloatEpsilon(epsilon float64) *StructMatcherBuilder {
+	b.matcher.FloatEpsilon = epsilon
+	return b
+}
+
+// NaNEqual make NaN floats equal to each other
+func (b *StructMatcherBuilder) NaNEqual() *StructMatcherBuilder {
+	b.matcher.NaNEqual = true
+	return b
+}
+
+// UnorderedSlices make the matcher match elements of slices and arrays in any order
+func (b *StructMatcherBuilder) UnorderedSlices() *StructMatcherBuilder {
+	b.matcher.UnorderedSlices = true
+	return b
+}
+
+// Comparer set an equality function for values of the same type as the example
+func (b *StructMatcherBuilder) Comparer(example interface{}, comparer Comparer) *StructMatcherBuilder {
+	if b.matcher.Comparers == nil {
+		b.matcher.Comparers = make(map[reflect.Type]Comparer)
+	}
+	b.matcher.Comparers[reflect.TypeOf(example)] = comparer
+	return b
+}
+
+// With apply the options to the matcher
+func (b *StructMatcherBuilder) With(opts ...MatchOption) *StructMatcherBuilder {
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Build return the matcher, it panics if some named fields do not exist
+func (b *StructMatcherBuilder) Build() StructMatcher {
+	sm, err := b.build()
+	if err != nil {
+		panic(err)
+	}
+	return sm
+}
+
+// BuildT return the matcher, the test fails if some named fields do not exist
+func (b *StructMatcherBuilder) BuildT(t gomock.TestHelper) StructMatcher {
+	t.Helper()
+	sm, err := b.build()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return sm
+}
+
+// build return a validated copy of the matcher, so the builder may be changed further
+func (b *StructMatcherBuilder) build() (StructMatcher, error) {
+	sm := b.matcher
+	sm.SkipFields = append([]string(nil), sm.SkipFields...)
+	if sm.MatcherFields != nil {
+		sm.MatcherFields = make(map[string]gomock.Matcher, len(b.matcher.MatcherFields))
+		for field, matcher := range b.matcher.MatcherFields {
+			sm.MatcherFields[field] = matcher
+		}
+	}
+	if sm.Comparers != nil {
+		sm.Comparers = make(map[reflect.Type]Comparer, len(b.matcher.Comparers))
+		for t, comparer := range b.matcher.Comparers {
+			sm.Comparers[t] = comparer
+		}
+	}
+	return sm, sm.Validate()
+}
+
+// Validate return an error if paths of SkipFields or MatcherFields do not exist in the type
+// of Matching. Paths are not checked if Matching is not a structure (e.g. a map of fields
+// or golden files are used) and in the cross-type mode, where paths may point to fields
+// of the checked structure
+func (sm StructMatcher) Validate() error {
+	t := reflect.TypeOf(sm.Matching)
+	if t == nil || sm.CrossType || indirectType(t).Kind() != reflect.Struct {
+		return nil
+	}
+
+	paths := append([]string{}, sm.SkipFields...)
+	for field := range sm.MatcherFields {
+		paths = append(paths, field)
+	}
+	sort.Strings(paths)
+
+	d := newStructDiff(sm)
+	unknown := make([]string, 0)
+	for _, path := range paths {
+		if !d.resolves(fieldPath{}, t, parseFieldPath(path), nil) {
+			unknown = append(unknown, strconv.Quote(path))
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("StructMatcher: %s has no fields %s", t, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// resolves return true if the rest of the path exists in the type, fields are named by
+// the diff rules and may be promoted from embedded structures, interfaces accept any path.
+// The stack keeps structures which embed the current one, so mutually embedded types are
+// walked once
+func (d *structDiff) resolves(path fieldPath, t reflect.Type, rest fieldPath, stack map[reflect.Type]bool) bool {
+	t = indirectType(t)
+	if len(rest) == 0 || t.Kind() == reflect.Interface {
+		return true
+	}
+
+	step := rest[0]
+	if step.index {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			if step.name != pathWildcard {
+				if _, err := strconv.Atoi(step.name); err != nil {
+					return false
+				}
+			}
+		case reflect.Map:
+		default:
+			return false
+		}
+		return d.resolves(path.append(step), t.Elem(), rest[1:], nil)
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if stack == nil {
+		stack = make(map[reflect.Type]bool)
+	}
+	stack[t] = true
+	defer delete(stack, t)
+	for _, field := range d.structFields(path, t) {
+		fieldType := t.Field(field.index).Type
+		last := field.path[len(field.path)-1]
+		if last.name == step.name && d.resolves(field.path, fieldType, rest[1:], nil) {
+			return true
+		}
+		embedded := indirectType(fieldType)
+		if last.embedded && embedded.Kind() == reflect.Struct && !stack[embedded] && d.resolves(field.path, embedded, rest, stack) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testHelper is a gomock.TestHelper which saves a failure message
+type testHelper struct {
+	message string
+}
+
+func (h *testHelper) Errorf(format string, args ...interface{}) {
+	h.message = fmt.Sprintf(format, args...)
+}
+
+func (h *testHelper) Fatalf(format string, args ...interface{}) {
+	h.message = fmt.Sprintf(format, args...)
+}
+
+func (h *testHelper) Helper() {}
+
+// TestCycleA and TestCycleB embed each other
+type TestCycleA struct {
+	*TestCycleB
+	Name string
+}
+
+type TestCycleB struct {
+	*TestCycleA
+	Code string
+}
+
+func Test_StructMatcherBuilder(t *testing.T) {
+	Convey("Test StructMatcher builder", t, func() {
+		now := time.Now()
+		model := TestModel{TestBaseModel: TestBaseModel{ID: 1, CreatedAt: now}, Name: "test"}
+
+		Convey("Test building", func() {
+			matcher := MatchStruct(model).
+				Skip("ID").
+				Field("CreatedAt", TimeMatcher{now}).
+				Field("Name", "test").
+				UnorderedSlices().
+				Build()
+			So(matcher.SkipFields, ShouldResemble, []string{"ID"})
+			So(matcher.MatcherFields, ShouldHaveLength, 2)
+			So(matcher.MatcherFields["Name"], ShouldResemble, gomock.Eq("test"))
+			So(matcher.UnorderedSlices, ShouldBeTrue)
+
+			changed := model
+			changed.ID = 2
+			changed.CreatedAt = now.Add(time.Second)
+			So(matcher.Matches(changed), ShouldBeTrue)
+			changed.Name = "another"
+			So(matcher.Matches(changed), ShouldBeFalse)
+		})
+		Convey("Test the builder does not change built matchers", func() {
+			builder := MatchStruct(model).Skip("ID")
+			matcher := builder.Build()
+			builder.Skip("Name").Field("CreatedAt", TimeMatcher{now})
+			So(matcher.SkipFields, ShouldResemble, []string{"ID"})
+			So(matcher.MatcherFields, ShouldBeNil)
+		})
+		Convey("Test validation of field paths", func() {
+			So(func() {
+				MatchStruct(model).Skip("Name", "TestBaseModel.ID", "CreatedAt", "testAudit.UpdatedAt", "UpdatedAt", "secret").Build()
+			}, ShouldNotPanic)
+			So(func() { MatchStruct(&model).Skip("Name").Build() }, ShouldNotPanic)
+			So(func() {
+				MatchStruct(TestNestedStruct{}).Skip("Owner.ID", "Items[*].ID", "Pairs[1].CreatedAt", "Meta[key].Any").Build()
+			}, ShouldNotPanic)
+			So(func() { MatchStruct(TestTaggedStruct{}).TagKey("json").Skip("created_at", "tags").Build() }, ShouldNotPanic)
+			So(func() { MatchStruct(map[string]interface{}{"Name": "test"}).Skip("Unknown").Build() }, ShouldNotPanic)
+			So(func() { MatchStruct(model).CrossType().Skip("Unknown").Build() }, ShouldNotPanic)
+
+			So(func() { MatchStruct(model).Skip("Nmae").Build() }, ShouldPanic)
+			_, err := MatchStruct(model).Skip("Nmae").build()
+			So(err.Error(), ShouldEqual, `StructMatcher: helpers.TestModel has no fields "Nmae"`)
+			_, err = MatchStruct(TestNestedStruct{}).Skip("Items[first]", "Owner.ID.Value", "Items[*].Name").Field("Onwer", nil).build()
+			So(err.Error(), ShouldEqual,
+				`StructMatcher: helpers.TestNestedStruct has no fields "Items[*].Name", "Items[first]", "Onwer", "Owner.ID.Value"`)
+			_, err = MatchStruct(TestTaggedStruct{}).TagKey("json").Skip("UserName", "Password").build()
+			So(err.Error(), ShouldEqual, `StructMatcher: helpers.TestTaggedStruct has no fields "Password", "UserName"`)
+			_, err = MatchStruct(model).SkipUnexported().Skip("secret").build()
+			So(err, ShouldNotBeNil)
+
+			So(func() {
+				MatchStruct(TestCycleA{}).Skip("Name", "Code", "TestCycleB.Code", "TestCycleB.TestCycleA.Name").Build()
+			}, ShouldNotPanic)
+			_, err = MatchStruct(TestCycleA{}).Skip("Unknown").build()
+			So(err.Error(), ShouldEqual, `StructMatcher: helpers.TestCycleA has no fields "Unknown"`)
+		})
+		Convey("Test failing the test on build", func() {
+			helper := &testHelper{}
+			MatchStruct(model).Skip("Name").BuildT(helper)
+			So(helper.message, ShouldBeEmpty)
+			MatchStruct(model).Skip("Nmae").BuildT(helper)
+			So(strings.Contains(helper.message, `"Nmae"`), ShouldBeTrue)
+		})
+	})
+}
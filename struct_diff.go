@@ -0,0 +1,747 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"unsafe"
+
+	"github.com/golang/mock/gomock"
+)
+
+// fieldMatcher is a GoMock matcher bound to a field path
+type fieldMatcher struct {
+	path     fieldPath
+	matcher  gomock.Matcher
+	rule     MatchRule   // the rule which is reported on mismatch
+	expected interface{} // the value which is reported as expected on mismatch
+	used     bool        // the path has been found in the checked data
+}
+
+// valueMatcher is a matcher of a raw value from partial Matching fields, numbers
+// and strings are converted to the type of the checked field if nothing is lost
+type valueMatcher struct {
+	value interface{}
+}
+
+// String return a string value of the expected value
+func (m valueMatcher) String() string {
+	return fmt.Sprintf("is equal to %v", m.value)
+}
+
+// Matches return true if the value is equal to the expected one
+func (m valueMatcher) Matches(x interface{}) bool {
+	if reflect.DeepEqual(m.value, x) {
+		return true
+	}
+	if m.value == nil || x == nil {
+		return false
+	}
+	value, ok := convertValue(reflect.ValueOf(m.value), reflect.TypeOf(x))
+	return ok && reflect.DeepEqual(value.Interface(), x)
+}
+
+// visit is a pair of compared references, it's used to stop on cyclic data
+type visit struct {
+	expected, actual uintptr
+	typ              reflect.Type
+}
+
+// structDiff walks through expected and actual values and collects mismatched fields
+type structDiff struct {
+	skipUnexported bool
+	partial        bool
+	tagKey         string
+	crossType      bool
+	nilEqualsEmpty bool
+	floatEpsilon   float64
+	nanEqual       bool
+	unordered      bool
+	comparers      map[reflect.Type]Comparer
+	skip           []fieldPath
+	matchers       []fieldMatcher
+	visited        map[visit]bool
+	mismatches     []Mismatch
+}
+
+// newStructDiff prepare a diff by StructMatcher rules
+func newStructDiff(sm StructMatcher) *structDiff {
+	d := &structDiff{
+		skipUnexported: sm.SkipUnexported,
+		partial:        sm.Partial,
+		tagKey:         sm.TagKey,
+		crossType:      sm.CrossType,
+		nilEqualsEmpty: sm.NilEqualsEmpty,
+		floatEpsilon:   sm.FloatEpsilon,
+		nanEqual:       sm.NaNEqual,
+		unordered:      sm.UnorderedSlices,
+		comparers:      sm.Comparers,
+		skip:           make([]fieldPath, 0, len(sm.SkipFields)),
+		matchers:       make([]fieldMatcher, 0, len(sm.MatcherFields)),
+		visited:        make(map[visit]bool),
+		mismatches:     make([]Mismatch, 0),
+	}
+	for _, field := range sm.SkipFields {
+		d.skip = append(d.skip, parseFieldPath(field))
+	}
+	for field, matcher := range sm.MatcherFields {
+		d.addMatcher(field, matcher, RuleMatcherFields, matcher)
+	}
+	if fields, ok := sm.Matching.(map[string]interface{}); ok {
+		for field, value := range fields {
+			if matcher, ok := value.(gomock.Matcher); ok {
+				d.addMatcher(field, matcher, RuleMatcherFields, matcher)
+			} else {
+				d.addMatcher(field, valueMatcher{value: value}, RuleDeepEqual, value)
+			}
+		}
+	}
+	sort.SliceStable(d.matchers, func(i, j int) bool {
+		return d.matchers[i].path.String() < d.matchers[j].path.String()
+	})
+	return d
+}
+
+// addMatcher bind the matcher to the field path
+func (d *structDiff) addMatcher(field string, matcher gomock.Matcher, rule MatchRule, expected interface{}) {
+	d.matchers = append(d.matchers, fieldMatcher{
+		path:     parseFieldPath(field),
+		matcher:  matcher,
+		rule:     rule,
+		expected: expected,
+	})
+}
+
+// report save a mismatch of the field
+func (d *structDiff) report(path fieldPath, rule MatchRule, expected, actual interface{}) {
+	d.mismatches = append(d.mismatches, Mismatch{
+		Path:     path.String(),
+		Rule:     rule,
+		Expected: expected,
+		Actual:   actual,
+	})
+}
+
+// skipped return true if the field should be ignored
+func (d *structDiff) skipped(path fieldPath) bool {
+	for _, pattern := range d.skip {
+		if path.matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matcher return a GoMock matcher for the field if there is one
+func (d *structDiff) matcher(path fieldPath) (*fieldMatcher, bool) {
+	for i := range d.matchers {
+		if path.matches(d.matchers[i].path) {
+			d.matchers[i].used = true
+			return &d.matchers[i], true
+		}
+	}
+	return nil, false
+}
+
+// match check the value by the field matcher
+func (d *structDiff) match(path fieldPath, fm *fieldMatcher, value interface{}) {
+	if !fm.matcher.Matches(value) {
+		d.report(path, fm.rule, fm.expected, value)
+	}
+}
+
+// hasRulesAt return true if some rules point to the field or to fields nested into it
+func (d *structDiff) hasRulesAt(path fieldPath) bool {
+	for _, fm := range d.matchers {
+		if path.matches(fm.path) {
+			return true
+		}
+	}
+	return d.hasRulesWithin(path)
+}
+
+// hasRulesWithin return true if some rules point to fields nested into the path
+func (d *structDiff) hasRulesWithin(path fieldPath) bool {
+	for _, pattern := range d.skip {
+		if path.within(pattern) {
+			return true
+		}
+	}
+	for _, fm := range d.matchers {
+		if path.within(fm.path) {
+			return true
+		}
+	}
+	return false
+}
+
+// seen return true if the pair of references has been already compared
+func (d *structDiff) seen(expected, actual reflect.Value) bool {
+	v := visit{expected: expected.Pointer(), actual: actual.Pointer(), typ: expected.Type()}
+	if d.visited[v] {
+		return true
+	}
+	d.visited[v] = true
+	return false
+}
+
+// compare check the actual value against the expected one, both values have the same type
+// unless the cross-type mode is on
+func (d *structDiff) compare(path fieldPath, expected, actual reflect.Value) {
+	if d.skipped(path) {
+		return
+	}
+	if fm, ok := d.matcher(path); ok {
+		d.match(path, fm, actual.Interface())
+		return
+	}
+	if expected.Type() != actual.Type() {
+		d.compareTypes(path, expected, actual)
+		return
+	}
+	if equal, ok := d.comparers[expected.Type()]; ok {
+		if !equal(expected.Interface(), actual.Interface()) {
+			d.report(path, RuleComparer, expected.Interface(), actual.Interface())
+		}
+		return
+	}
+
+	switch expected.Kind() {
+	case reflect.Ptr:
+		if expected.IsNil() || actual.IsNil() {
+			d.compareDeep(path, expected, actual)
+			return
+		}
+		if expected.Pointer() == actual.Pointer() && !d.hasRulesWithin(path) {
+			return
+		}
+		if !d.seen(expected, actual) {
+			d.compare(path, expected.Elem(), actual.Elem())
+		}
+	case reflect.Interface:
+		if expected.IsNil() || actual.IsNil() || !d.crossType && expected.Elem().Type() != actual.Elem().Type() {
+			d.compareDeep(path, expected, actual)
+			return
+		}
+		d.compare(path, expected.Elem(), actual.Elem())
+	case reflect.Struct:
+		d.compareStruct(path, expected, actual)
+	case reflect.Slice:
+		if d.bothEmpty(expected, actual) {
+			return
+		}
+		if expected.IsNil() != actual.IsNil() || !d.unordered && expected.Len() != actual.Len() {
+			d.compareDeep(path, expected, actual)
+			return
+		}
+		if expected.Pointer() == actual.Pointer() && !d.hasRulesWithin(path) {
+			return
+		}
+		d.compareSequences(path, expected, actual)
+	case reflect.Array:
+		d.compareSequences(path, expected, actual)
+	case reflect.Map:
+		if d.bothEmpty(expected, actual) {
+			return
+		}
+		if expected.IsNil() != actual.IsNil() {
+			d.compareDeep(path, expected, actual)
+			return
+		}
+		if expected.Pointer() == actual.Pointer() && !d.hasRulesWithin(path) || d.seen(expected, actual) {
+			return
+		}
+		d.compareMaps(path, expected, actual)
+	case reflect.Float32, reflect.Float64:
+		d.compareFloats(path, expected, actual)
+	default:
+		d.compareDeep(path, expected, actual)
+	}
+}
+
+// compareTypes compare values of different types, pointers and interfaces are dereferenced,
+// convertible values are converted, structures are compared by field names
+func (d *structDiff) compareTypes(path fieldPath, expected, actual reflect.Value) {
+	if !d.crossType {
+		d.report(path, RuleType, expected.Type(), actual.Type())
+		return
+	}
+	if convertible(actual.Type(), expected.Type()) {
+		d.compareConverted(path, expected, actual)
+		return
+	}
+	if isReference(expected) || isReference(actual) {
+		if isNilReference(expected) || isNilReference(actual) {
+			if isNilReference(expected) != isNilReference(actual) {
+				d.report(path, RuleDeepEqual, expected.Interface(), actual.Interface())
+			}
+			return
+		}
+		d.compare(path, dereference(expected), dereference(actual))
+		return
+	}
+
+	switch {
+	case expected.Kind() == reflect.Struct && actual.Kind() == reflect.Struct:
+		d.compareFieldsByName(path, expected, actual)
+	case (expected.Kind() == reflect.Slice || expected.Kind() == reflect.Array) &&
+		(actual.Kind() == reflect.Slice || actual.Kind() == reflect.Array):
+		if d.bothEmpty(expected, actual) {
+			return
+		}
+		if !d.unordered && expected.Len() != actual.Len() || isNilReference(expected) != isNilReference(actual) {
+			d.report(path, RuleDeepEqual, expected.Interface(), actual.Interface())
+			return
+		}
+		d.compareSequences(path, expected, actual)
+	case expected.Kind() == reflect.Map && actual.Kind() == reflect.Map:
+		if d.bothEmpty(expected, actual) {
+			return
+		}
+		if expected.IsNil() != actual.IsNil() {
+			d.report(path, RuleDeepEqual, expected.Interface(), actual.Interface())
+			return
+		}
+		d.compareMaps(path, expected, actual)
+	default:
+		d.report(path, RuleType, expected.Type(), actual.Type())
+	}
+}
+
+// compareConverted compare numbers or strings of different types in the type which keeps both
+// values exactly, e.g. int64 257 differs from int8 1 and float64 3.99 differs from int 3.
+// Mismatches report the values as they are instead of the converted ones
+func (d *structDiff) compareConverted(path fieldPath, expected, actual reflect.Value) {
+	n := len(d.mismatches)
+	if converted, ok := convertValue(actual, expected.Type()); ok {
+		d.compare(path, expected, converted)
+	} else if converted, ok := convertValue(expected, actual.Type()); ok {
+		d.compare(path, converted, actual)
+	} else {
+		d.report(path, RuleDeepEqual, expected.Interface(), actual.Interface())
+	}
+	for i := n; i < len(d.mismatches); i++ {
+		if d.mismatches[i].Path == path.String() {
+			d.mismatches[i].Expected, d.mismatches[i].Actual = expected.Interface(), actual.Interface()
+		}
+	}
+}
+
+// compareFieldsByName compare structures of different types by names of their fields,
+// fields of embedded structures are compared as promoted ones
+func (d *structDiff) compareFieldsByName(path fieldPath, expected, actual reflect.Value) {
+	actualFields := d.namedFields(path, actual, make(map[reflect.Type]bool))
+	byName := make(map[string]namedField, len(actualFields))
+	for _, field := range actualFields {
+		byName[field.name] = field
+	}
+
+	for _, field := range d.namedFields(path, expected, make(map[reflect.Type]bool)) {
+		actualField, ok := byName[field.name]
+		delete(byName, field.name)
+		if d.partial && field.value.IsZero() && !d.hasRulesAt(field.path) {
+			continue
+		}
+		if !ok {
+			if !d.skipped(field.path) {
+				d.report(field.path, RuleMissingField, field.value.Interface(), nil)
+			}
+			continue
+		}
+		if field.omitEmpty && isEmptyValue(field.value) && isEmptyValue(actualField.value) && !d.hasRulesAt(field.path) {
+			continue
+		}
+		d.compare(field.path, field.value, actualField.value)
+	}
+	for _, field := range actualFields {
+		if _, ok := byName[field.name]; ok && !d.skipped(field.path) {
+			d.report(field.path, RuleExtraField, nil, field.value.Interface())
+		}
+	}
+}
+
+// namedField is a field of a structure with its value, fields of embedded structures are flattened
+type namedField struct {
+	name      string
+	path      fieldPath
+	value     reflect.Value
+	omitEmpty bool
+}
+
+// namedFields return a flat list of fields of the structure including fields of embedded
+// structures, fields of the outer structure shadow the promoted ones. The stack keeps structures
+// which embed the current one, so mutually embedded types are flattened once
+func (d *structDiff) namedFields(path fieldPath, v reflect.Value, stack map[reflect.Type]bool) []namedField {
+	v = addressable(v)
+	stack[v.Type()] = true
+	defer delete(stack, v.Type())
+	direct := make([]namedField, 0, v.NumField())
+	promoted := make([]namedField, 0)
+	for _, field := range d.structFields(path, v.Type()) {
+		value := exported(v.Field(field.index))
+		step := field.path[len(field.path)-1]
+		if embedded := indirectType(value.Type()); step.embedded && embedded.Kind() == reflect.Struct && !stack[embedded] {
+			for value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					value = reflect.Zero(value.Type().Elem())
+					continue
+				}
+				value = value.Elem()
+			}
+			promoted = append(promoted, d.namedFields(field.path, value, stack)...)
+			continue
+		}
+		direct = append(direct, namedField{name: step.name, path: field.path, value: value, omitEmpty: field.omitEmpty})
+	}
+
+	names := make(map[string]bool, len(direct))
+	for _, field := range direct {
+		names[field.name] = true
+	}
+	for _, field := range promoted {
+		if !names[field.name] {
+			names[field.name] = true
+			direct = append(direct, field)
+		}
+	}
+	return direct
+}
+
+// compareDeep compare the values as a whole
+func (d *structDiff) compareDeep(path fieldPath, expected, actual reflect.Value) {
+	if !reflect.DeepEqual(expected.Interface(), actual.Interface()) {
+		d.report(path, RuleDeepEqual, expected.Interface(), actual.Interface())
+	}
+}
+
+// compareStruct compare the structures field by field, structures without exported
+// fields (e.g. time.Time) are compared as a whole unless some rules point inside them
+func (d *structDiff) compareStruct(path fieldPath, expected, actual reflect.Value) {
+	if len(path) > 0 && !d.hasRulesWithin(path) && !hasExportedFields(expected.Type()) {
+		d.compareDeep(path, expected, actual)
+		return
+	}
+	expected, actual = addressable(expected), addressable(actual)
+	for _, field := range d.structFields(path, expected.Type()) {
+		value, actualValue := exported(expected.Field(field.index)), exported(actual.Field(field.index))
+		if d.partial && value.IsZero() && !d.hasRulesAt(field.path) {
+			continue
+		}
+		if field.omitEmpty && isEmptyValue(value) && isEmptyValue(actualValue) && !d.hasRulesAt(field.path) {
+			continue
+		}
+		d.compare(field.path, value, actualValue)
+	}
+}
+
+// structField is a field of a structure with its path
+type structField struct {
+	index     int
+	path      fieldPath
+	omitEmpty bool
+}
+
+// structFields return a list of the structure fields which should be checked, fields are
+// named by the tag key if it's set, unexported fields are not a part of tagged payloads
+func (d *structDiff) structFields(path fieldPath, t reflect.Type) []structField {
+	fields := make([]structField, 0, t.NumField())
+	owner, index := path.promotion()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		unexported := field.PkgPath != "" && !field.Anonymous
+		tag := fieldTag{name: field.Name, inline: field.Anonymous}
+		if d.tagKey != "" {
+			tag = parseFieldTag(field, d.tagKey)
+		}
+		if tag.skip || unexported && (d.skipUnexported || d.tagKey != "") {
+			continue
+		}
+		step := pathStep{name: tag.name}
+		if owner != nil {
+			step.promoted = isPromoted(owner, index, i, field.Name)
+		}
+		if tag.inline {
+			step.embedded, step.owner, step.fieldIndex = true, t, i
+		}
+		fields = append(fields, structField{index: i, path: path.append(step), omitEmpty: tag.omitEmpty})
+	}
+	return fields
+}
+
+// bothEmpty return true if nil and empty slices and maps are equal and both values are empty
+func (d *structDiff) bothEmpty(expected, actual reflect.Value) bool {
+	return d.nilEqualsEmpty && expected.Len() == 0 && actual.Len() == 0
+}
+
+// compareFloats compare floats with the configured epsilon, NaN values are equal if it's allowed
+func (d *structDiff) compareFloats(path fieldPath, expected, actual reflect.Value) {
+	a, b := expected.Float(), actual.Float()
+	if a == b || d.nanEqual && math.IsNaN(a) && math.IsNaN(b) || math.Abs(a-b) <= d.floatEpsilon {
+		return
+	}
+	d.report(path, RuleDeepEqual, expected.Interface(), actual.Interface())
+}
+
+// compareSequences compare slices or arrays in order or regardless of the order of elements
+func (d *structDiff) compareSequences(path fieldPath, expected, actual reflect.Value) {
+	if d.unordered {
+		d.compareUnordered(path, expected, actual)
+		return
+	}
+	d.compareElements(path, expected, actual)
+}
+
+// compareUnordered pair each expected element with an equal actual one in any order, elements
+// without pairs are compared with each other in order, the rest are reported as missed or extra
+func (d *structDiff) compareUnordered(path fieldPath, expected, actual reflect.Value) {
+	paired := make([]bool, actual.Len())
+	unpaired := make([]int, 0)
+	for i := 0; i < expected.Len(); i++ {
+		found := false
+		for j := 0; j < actual.Len() && !found; j++ {
+			if !paired[j] && d.equal(path.index(strconv.Itoa(i)), expected.Index(i), actual.Index(j)) {
+				paired[j], found = true, true
+			}
+		}
+		if !found {
+			unpaired = append(unpaired, i)
+		}
+	}
+
+	for j := 0; j < actual.Len(); j++ {
+		if paired[j] {
+			continue
+		}
+		if len(unpaired) > 0 {
+			i := unpaired[0]
+			unpaired = unpaired[1:]
+			d.compare(path.index(strconv.Itoa(i)), expected.Index(i), actual.Index(j))
+			continue
+		}
+		elementPath := path.index(strconv.Itoa(j))
+		if !d.skipped(elementPath) {
+			d.report(elementPath, RuleDeepEqual, nil, actual.Index(j).Interface())
+		}
+	}
+	for _, i := range unpaired {
+		d.missing(path.index(strconv.Itoa(i)), expected.Index(i))
+	}
+}
+
+// equal return true if the values are equal by the diff rules, mismatches are not reported
+func (d *structDiff) equal(path fieldPath, expected, actual reflect.Value) bool {
+	trial := *d
+	trial.visited = make(map[visit]bool)
+	trial.mismatches = make([]Mismatch, 0)
+	trial.compare(path, expected, actual)
+	return len(trial.mismatches) == 0
+}
+
+// compareElements compare slices or arrays with the same length element by element
+func (d *structDiff) compareElements(path fieldPath, expected, actual reflect.Value) {
+	for i := 0; i < expected.Len(); i++ {
+		d.compare(path.index(strconv.Itoa(i)), expected.Index(i), actual.Index(i))
+	}
+}
+
+// compareMaps compare maps key by key, missed and extra keys are reported separately
+func (d *structDiff) compareMaps(path fieldPath, expected, actual reflect.Value) {
+	for _, key := range sortedMapKeys(expected) {
+		keyPath := path.index(fmt.Sprint(key.Interface()))
+		value := mapIndex(actual, key)
+		if !value.IsValid() {
+			d.missing(keyPath, expected.MapIndex(key))
+			continue
+		}
+		d.compare(keyPath, expected.MapIndex(key), value)
+	}
+	for _, key := range sortedMapKeys(actual) {
+		if mapIndex(expected, key).IsValid() {
+			continue
+		}
+		keyPath := path.index(fmt.Sprint(key.Interface()))
+		if !d.skipped(keyPath) {
+			d.report(keyPath, RuleDeepEqual, nil, actual.MapIndex(key).Interface())
+		}
+	}
+}
+
+// inspect check only the fields of the actual value which have matchers, it's used for
+// partial Matching fields set by a map
+func (d *structDiff) inspect(path fieldPath, actual reflect.Value) {
+	if d.skipped(path) {
+		return
+	}
+	if fm, ok := d.matcher(path); ok {
+		d.match(path, fm, actual.Interface())
+		return
+	}
+	if !d.hasRulesWithin(path) {
+		return
+	}
+
+	switch actual.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !actual.IsNil() {
+			d.inspect(path, actual.Elem())
+		}
+	case reflect.Struct:
+		actual = addressable(actual)
+		for _, field := range d.structFields(path, actual.Type()) {
+			d.inspect(field.path, exported(actual.Field(field.index)))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < actual.Len(); i++ {
+			d.inspect(path.index(strconv.Itoa(i)), actual.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range sortedMapKeys(actual) {
+			d.inspect(path.index(fmt.Sprint(key.Interface())), actual.MapIndex(key))
+		}
+	}
+}
+
+// unused report matchers of fields which have not been found in the checked data,
+// paths with wildcards are not reported, e.g. "Items[*].ID" is fine for empty Items
+func (d *structDiff) unused() {
+	for i := range d.matchers {
+		fm := &d.matchers[i]
+		if fm.used || fm.path.hasWildcard() {
+			continue
+		}
+		d.match(fm.path, fm, nil)
+	}
+}
+
+// missing report the expected value which has no pair in the actual data
+func (d *structDiff) missing(path fieldPath, expected reflect.Value) {
+	if d.skipped(path) {
+		return
+	}
+	if fm, ok := d.matcher(path); ok {
+		d.match(path, fm, nil)
+		return
+	}
+	d.report(path, RuleDeepEqual, expected.Interface(), nil)
+}
+
+// mapIndex return a value of the map by the key, the key is converted to the map key type if it's possible
+func mapIndex(m, key reflect.Value) reflect.Value {
+	if !key.Type().AssignableTo(m.Type().Key()) {
+		converted, ok := convertValue(key, m.Type().Key())
+		if !ok {
+			return reflect.Value{}
+		}
+		key = converted
+	}
+	return m.MapIndex(key)
+}
+
+// sortedMapKeys return map keys in a stable order to make reports reproducible
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+// hasExportedFields return true if the structure has at least one exported field
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// convertible return true if values of the type may be converted to the given type of the same kind,
+// numbers to numbers and strings to strings
+func convertible(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	return from.Kind() == to.Kind() || kindClass(from.Kind()) != 0 && kindClass(from.Kind()) == kindClass(to.Kind())
+}
+
+// convertValue convert the value to the given type of the same kind, numbers and strings are
+// converted only if nothing is lost, i.e. the converted value is converted back to the same one
+// (e.g. 257 is not converted to int8 and 3.5 is not converted to int)
+func convertValue(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if !convertible(v.Type(), t) {
+		return v, false
+	}
+	converted := v.Convert(t)
+	if kindClass(v.Kind()) == 0 {
+		return converted, true
+	}
+	back := converted.Convert(v.Type())
+	if back.Interface() != v.Interface() && !(isNaN(v) && isNaN(back)) {
+		return v, false
+	}
+	return converted, true
+}
+
+// isNaN return true if the value is a NaN float
+func isNaN(v reflect.Value) bool {
+	return (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) && math.IsNaN(v.Float())
+}
+
+// kindClass return a class of similar kinds which values can be converted to each other
+func kindClass(kind reflect.Kind) int {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return 1
+	case reflect.Complex64, reflect.Complex128:
+		return 2
+	case reflect.String:
+		return 3
+	}
+	return 0
+}
+
+// isReference return true for pointers and interfaces
+func isReference(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface
+}
+
+// isNilReference return true for nil pointers, interfaces, slices and maps
+func isNilReference(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	}
+	return false
+}
+
+// dereference return a value which the pointer or the interface refers to
+func dereference(v reflect.Value) reflect.Value {
+	if isReference(v) {
+		return v.Elem()
+	}
+	return v
+}
+
+// addressable return the value itself if it's addressable or its addressable copy
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// exported return a value of an unexported field which can be used as a regular one,
+// the field is only read, so it's safe to work around its read-only flag
+func exported(v reflect.Value) reflect.Value {
+	if v.CanInterface() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
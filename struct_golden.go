@@ -0,0 +1,123 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// UpdateGoldenEnv is a name of the environment variable which makes StructMatcher rewrite golden files, eg:
+//
+//	UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// UpdateGolden makes StructMatcher rewrite golden files from actual values, it's set by the -update
+// flag after RegisterUpdateFlag
+var UpdateGolden bool
+
+// goldenWritten keep paths of golden files which have been rewritten by the test binary
+var goldenWritten sync.Map
+
+// RegisterUpdateFlag will register the -update flag which sets UpdateGolden. The package doesn't
+// define flags by itself, call it from TestMain or init of a test package, eg:
+//
+//	func init() {
+//		helpers.RegisterUpdateFlag()
+//	}
+//
+// and run tests with "go test ./... -update". Registering the flag twice is allowed
+func RegisterUpdateFlag() {
+	if flag.Lookup("update") != nil {
+		return
+	}
+	flag.BoolVar(&UpdateGolden, "update", false, "rewrite golden files of StructMatcher")
+}
+
+// GoldenPath return a path to the golden file of the matcher: testdata/<Golden>.golden.json,
+// an absolute Golden path is used without testdata (e.g. a file in t.TempDir())
+func (sm StructMatcher) GoldenPath() string {
+	if filepath.IsAbs(sm.Golden) {
+		return sm.Golden + ".golden.json"
+	}
+	return filepath.Join("testdata", sm.Golden+".golden.json")
+}
+
+// isGoldenUpdate return true if golden files should be rewritten
+func isGoldenUpdate() bool {
+	if UpdateGolden {
+		return true
+	}
+	update, _ := strconv.ParseBool(os.Getenv(UpdateGoldenEnv))
+	return update
+}
+
+// goldenMismatches compare the structure with the golden file, both sides are compared after
+// a JSON round-trip, so the data which JSON cannot keep (e.g. monotonic clock) is ignored.
+// In the update mode the first checked structure is written to the golden file once,
+// all checks are compared with it, so other calls don't overwrite it
+func (sm StructMatcher) goldenMismatches(verifiableData interface{}) []Mismatch {
+	passed := reflect.ValueOf(verifiableData)
+	for passed.Kind() == reflect.Ptr && !passed.IsNil() {
+		passed = passed.Elem()
+	}
+	if passed.Kind() != reflect.Struct {
+		return []Mismatch{{
+			Rule:     RuleType,
+			Expected: reflect.Struct,
+			Actual:   reflect.TypeOf(verifiableData),
+		}}
+	}
+
+	path := sm.GoldenPath()
+	if isGoldenUpdate() {
+		if _, written := goldenWritten.LoadOrStore(path, true); !written {
+			if err := writeGolden(path, verifiableData); err != nil {
+				goldenWritten.Delete(path)
+				return []Mismatch{{Rule: RuleGolden, Expected: path, Actual: err}}
+			}
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return []Mismatch{{Rule: RuleGolden, Expected: path, Actual: err}}
+	}
+	expected := reflect.New(passed.Type())
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(expected.Interface()); err != nil {
+		return []Mismatch{{Rule: RuleGolden, Expected: path, Actual: err}}
+	}
+
+	data, err = json.Marshal(passed.Interface())
+	if err != nil {
+		return []Mismatch{{Rule: RuleGolden, Expected: path, Actual: err}}
+	}
+	actual := reflect.New(passed.Type())
+	if err = json.Unmarshal(data, actual.Interface()); err != nil {
+		return []Mismatch{{Rule: RuleGolden, Expected: path, Actual: err}}
+	}
+
+	golden := sm
+	golden.Golden = ""
+	golden.Matching = expected.Elem().Interface()
+	return golden.Mismatches(actual.Elem().Interface())
+}
+
+// writeGolden save the value as an indented JSON document
+func writeGolden(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
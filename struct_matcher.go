@@ -4,97 +4,198 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/golang/mock/gomock"
 )
 
+// MatchRule is a name of the StructMatcher rule which has been applied to a field
+type MatchRule string
+
+const (
+	// RuleType the checked value has a type which differs from the Matching one
+	RuleType MatchRule = "Type"
+	// RuleDeepEqual the field has been compared via reflect.DeepEqual
+	RuleDeepEqual MatchRule = "DeepEqual"
+	// RuleSkipFields the field has been ignored because of SkipFields
+	RuleSkipFields MatchRule = "SkipFields"
+	// RuleMatcherFields the field has been checked via a GoMock matcher from MatcherFields
+	RuleMatcherFields MatchRule = "MatcherFields"
+	// RuleMissingField the field of Matching has no pair in the checked structure (cross-type mode)
+	RuleMissingField MatchRule = "MissingField"
+	// RuleExtraField the field of the checked structure has no pair in Matching (cross-type mode)
+	RuleExtraField MatchRule = "ExtraField"
+	// RuleGolden the golden file cannot be read or written
+	RuleGolden MatchRule = "Golden"
+	// RuleComparer the field has been compared via a function from Comparers
+	RuleComparer MatchRule = "Comparer"
+)
+
+// Mismatch describes a single field which has not passed the StructMatcher check
+type Mismatch struct {
+	Path     string      // a path to the field, empty for the whole value
+	Rule     MatchRule   // the rule which has been applied to the field
+	Expected interface{} // an expected value or a matcher
+	Actual   interface{} // an actual value
+}
+
+// String return a human readable description of the mismatch
+func (m Mismatch) String() string {
+	path := m.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return fmt.Sprintf("%s [%s]: expected %v, got %v", path, m.Rule, m.Expected, m.Actual)
+}
+
+// Comparer return true if the values of the same type are equal
+type Comparer func(expected, actual interface{}) bool
+
 // StructMatcher can be used for structures checking, includes:
+//
+// SkipFields and MatcherFields accept dotted/indexed paths to nested fields, e.g. "Owner.CreatedAt",
+// "Items[*].ID" (any element of a slice, an array or a map) or "Meta[key]" (a certain map key or index).
+// Fields of embedded structs may be addressed by their promoted names as well as by full paths.
+// Unexported fields are compared as the exported ones unless SkipUnexported is set.
+//
+// If TagKey is set (e.g. "json", "pg", "sql" or "db"), fields are addressed and reported by the names
+// from this struct tag instead of Go names: fields tagged with "-" and unexported fields are ignored,
+// empty "omitempty" fields are equal to each other, untagged fields are named the same way as
+// the corresponding package does it (e.g. "created_at" for go-pg)
+//
+// If CrossType is set, Matching and the checked structure may have different types (e.g. a request DTO
+// and a DB model), fields are matched by names (or tag names), missed and extra fields are reported,
+// pointers are dereferenced and values of convertible types (e.g. int32 and int64) are converted
+// if nothing is lost (e.g. int64 257 doesn't match int8 1).
+//
+// Matching may be a map[string]interface{} of field paths to raw values or GoMock matchers,
+// in this case only the listed fields are checked, other fields of the structure are ignored.
+//
+// Equality of values may be relaxed: NilEqualsEmpty makes nil and empty slices and maps equal,
+// FloatEpsilon and NaNEqual change comparison of floats, UnorderedSlices ignores the order of
+// slice and array elements, Comparers set custom equality functions for values of certain types.
+//
+// If Golden is set, the expected structure is loaded from testdata/<Golden>.golden.json instead of
+// Matching, SkipFields and MatcherFields are applied on top of it. Run tests with UPDATE_GOLDEN=1
+// (or -update, see RegisterUpdateFlag) to write golden files from the first checked values
 type StructMatcher struct {
-	Matching      interface{}               // set of fields for checking
-	SkipFields    []string                  // these fields will be ignored
-	MatcherFields map[string]gomock.Matcher // should be checked via GoMock matcher
+	Matching        interface{}               // set of fields for checking
+	SkipFields      []string                  // these fields will be ignored
+	MatcherFields   map[string]gomock.Matcher // should be checked via GoMock matcher
+	SkipUnexported  bool                      // unexported fields will be ignored
+	Partial         bool                      // zero fields of Matching will be ignored
+	TagKey          string                    // fields are addressed by names from this struct tag
+	CrossType       bool                      // structures of different types are matched by field names
+	Golden          string                    // a name of the golden file with the expected structure
+	NilEqualsEmpty  bool                      // nil and empty slices and maps are equal
+	FloatEpsilon    float64                   // floats are equal if they differ not more than by epsilon
+	NaNEqual        bool                      // NaN floats are equal to each other
+	UnorderedSlices bool                      // elements of slices and arrays are matched in any order
+	Comparers       map[reflect.Type]Comparer // equality functions for values of certain types
 }
 
 // String return a string value of matching fields
 func (sm StructMatcher) String() string {
+	if sm.Golden != "" {
+		return fmt.Sprintf("StructMatcher to golden file %s", sm.GoldenPath())
+	}
 	return fmt.Sprintf("StructMatcher to %v", sm.Matching)
 }
 
 // Matches Match all fields of an input structure to all fields in the saved structure. if they are equal => return true
 func (sm StructMatcher) Matches(verifiableData interface{}) bool {
+	return len(sm.Mismatches(verifiableData)) == 0
+}
 
-	verifiableData, matching, success := toExampleKind(verifiableData, sm.Matching, reflect.Struct)
-	if !success {
-		return success
+// Got implements gomock.GotFormatter, so a failed expectation prints the passed value
+// together with the list of mismatched fields
+func (sm StructMatcher) Got(verifiableData interface{}) string {
+	mismatches := sm.Mismatches(verifiableData)
+	if len(mismatches) == 0 {
+		return fmt.Sprintf("%v", verifiableData)
 	}
-	v := reflect.ValueOf(matching)
-	passed := reflect.ValueOf(verifiableData)
-	for i := 0; i < v.NumField(); i++ {
-		if sm.SkipFields != nil {
-			if StringInSlice(v.Type().Field(i).Name, sm.SkipFields) {
-				continue
-			}
-		}
-		if sm.MatcherFields != nil {
-			if matcher, ok := sm.MatcherFields[v.Type().Field(i).Name]; ok {
-				if matcher.Matches(passed.Field(i).Interface()) {
-					continue
-				} else {
-					return false
-				}
-			}
-		}
-
-		if !reflect.DeepEqual(passed.Field(i).Interface(), v.Field(i).Interface()) {
-			return false
-		}
-
+	lines := make([]string, 0, len(mismatches)+2)
+	lines = append(lines, fmt.Sprintf("%v", verifiableData), "mismatched fields:")
+	for _, m := range mismatches {
+		lines = append(lines, "\t"+m.String())
 	}
-	return true
+	return strings.Join(lines, "\n")
 }
 
-// toType return convertibleData which has been converted to the "kind" type
-func toType(convertibleData interface{}, kind interface{}) (changed interface{}, kinds []reflect.Kind, err error) {
-	kinds = make([]reflect.Kind, 0)
-	for {
-		t := reflect.TypeOf(convertibleData)
-		kinds = append(kinds, t.Kind())
-		if t.Kind() == reflect.Ptr {
-			convertibleData = reflect.ValueOf(convertibleData).Elem().Interface()
-			continue
-		}
-		if t != reflect.TypeOf(kind) {
-			err = errors.New("data type conversion error")
-			return
-		}
-		break
+// Mismatches return a list of fields which do not match the saved structure, it's empty when the input matches
+func (sm StructMatcher) Mismatches(verifiableData interface{}) []Mismatch {
+	if sm.Golden != "" {
+		return sm.goldenMismatches(verifiableData)
+	}
+	if _, ok := sm.Matching.(map[string]interface{}); ok {
+		return sm.partialMismatches(verifiableData)
+	}
+	if sm.CrossType {
+		return sm.crossTypeMismatches(verifiableData)
+	}
+	passedData, matching, success := toExampleKind(verifiableData, sm.Matching, reflect.Struct)
+	if !success {
+		return []Mismatch{{
+			Rule:     RuleType,
+			Expected: reflect.TypeOf(sm.Matching),
+			Actual:   reflect.TypeOf(verifiableData),
+		}}
 	}
 
-	changed = convertibleData
-	return
-}
+	if reflect.TypeOf(passedData) != reflect.TypeOf(matching) {
+		return []Mismatch{{
+			Rule:     RuleType,
+			Expected: reflect.TypeOf(sm.Matching),
+			Actual:   reflect.TypeOf(verifiableData),
+		}}
+	}
 
-// toExample try to cast a and b values to the same data type from an example
-func toExample(a, b interface{}, example interface{}) (exampleA, exampleB interface{}, success bool) {
+	d := newStructDiff(sm)
+	d.compare(fieldPath{}, reflect.ValueOf(matching), reflect.ValueOf(passedData))
+	return d.mismatches
+}
 
-	if a == nil || b == nil {
-		return
+// crossTypeMismatches dereference Matching and the checked structure independently,
+// so e.g. a DTO matches a pointer to a DB model
+func (sm StructMatcher) crossTypeMismatches(verifiableData interface{}) []Mismatch {
+	matching, passed := reflect.ValueOf(sm.Matching), reflect.ValueOf(verifiableData)
+	for matching.Kind() == reflect.Ptr && !matching.IsNil() {
+		matching = matching.Elem()
 	}
-	b, bKinds, err := toType(b, example)
-	if err != nil {
-		return
+	for passed.Kind() == reflect.Ptr && !passed.IsNil() {
+		passed = passed.Elem()
 	}
-	a, aKinds, err := toType(a, example)
-	if err != nil {
-		return
+	if matching.Kind() != reflect.Struct || passed.Kind() != reflect.Struct {
+		return []Mismatch{{
+			Rule:     RuleType,
+			Expected: reflect.TypeOf(sm.Matching),
+			Actual:   reflect.TypeOf(verifiableData),
+		}}
 	}
-	if len(bKinds) != len(aKinds) {
-		return
+
+	d := newStructDiff(sm)
+	d.compare(fieldPath{}, matching, passed)
+	return d.mismatches
+}
+
+// partialMismatches check the fields listed in the Matching map only
+func (sm StructMatcher) partialMismatches(verifiableData interface{}) []Mismatch {
+	passed := reflect.ValueOf(verifiableData)
+	for passed.Kind() == reflect.Ptr && !passed.IsNil() {
+		passed = passed.Elem()
 	}
-	success = true
-	exampleA = a
-	exampleB = b
-	return
+	if passed.Kind() != reflect.Struct {
+		return []Mismatch{{
+			Rule:     RuleType,
+			Expected: reflect.Struct,
+			Actual:   reflect.TypeOf(verifiableData),
+		}}
+	}
+
+	d := newStructDiff(sm)
+	d.inspect(fieldPath{}, passed)
+	d.unused()
+	return d.mismatches
 }
 
 // toKind return convertibleData which has been converted to "kind"
@@ -108,8 +209,6 @@ func toKind(convertibleData interface{}, kind reflect.Kind) (changed interface{}
 			continue
 		}
 		if t.Kind() != kind {
-			fmt.Println()
-			fmt.Println(t.Kind())
 			err = errors.New("data type conversion error")
 			return
 		}
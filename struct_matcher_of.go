@@ -0,0 +1,201 @@
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+
+	"github.com/golang/mock/gomock"
+)
+
+// FieldOf is an accessor of a field of T which return a pointer to the field, eg:
+//
+//	func(u *User) interface{} { return &u.CreatedAt }
+//
+// Fields of nested structures and of structures referred by pointers are supported as well
+// as elements of arrays, elements of slices and maps are not
+type FieldOf[T any] func(*T) interface{}
+
+// FieldMatcherOf is a GoMock matcher bound to a field of T
+type FieldMatcherOf[T any] struct {
+	Field   FieldOf[T]
+	Matcher gomock.Matcher
+}
+
+// MatchField bind the matcher to the field, raw values are compared via gomock.Eq
+func MatchField[T any](field FieldOf[T], matcher interface{}) FieldMatcherOf[T] {
+	return FieldMatcherOf[T]{Field: field, Matcher: toMatcher(matcher)}
+}
+
+// StructMatcherOf is a typed StructMatcher: the type of Matching is checked at compile time and
+// fields are set by accessors instead of string paths, eg:
+//
+//	helpers.StructMatcherOf[User]{
+//		Matching:   user,
+//		SkipFields: []helpers.FieldOf[User]{func(u *User) interface{} { return &u.ID }},
+//		MatcherFields: []helpers.FieldMatcherOf[User]{
+//			helpers.MatchField(func(u *User) interface{} { return &u.CreatedAt }, helpers.TimeMatcher{now}),
+//		},
+//	}.BuildT(t)
+//
+// Both T and *T values are accepted by the matcher. Other StructMatcher settings are set by Options.
+// Build and BuildT resolve accessors once and fail on invalid ones, a matcher which has not been
+// built resolves accessors on every call and panics if an accessor does not point to a field of T
+type StructMatcherOf[T any] struct {
+	Matching      T
+	SkipFields    []FieldOf[T]
+	MatcherFields []FieldMatcherOf[T]
+	Options       []MatchOption
+
+	resolved *StructMatcher // the untyped matcher saved by Build and BuildT
+}
+
+// Build return the matcher with resolved accessors, it panics if some accessors or fields are invalid
+func (sm StructMatcherOf[T]) Build() StructMatcherOf[T] {
+	matcher, err := sm.resolve()
+	if err != nil {
+		panic(err)
+	}
+	sm.resolved = &matcher
+	return sm
+}
+
+// BuildT return the matcher with resolved accessors, the test fails if some accessors or fields are invalid
+func (sm StructMatcherOf[T]) BuildT(t gomock.TestHelper) StructMatcherOf[T] {
+	t.Helper()
+	matcher, err := sm.resolve()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	sm.resolved = &matcher
+	return sm
+}
+
+// StructMatcher return an untyped StructMatcher with paths of the fields
+func (sm StructMatcherOf[T]) StructMatcher() (StructMatcher, error) {
+	if sm.resolved != nil {
+		return *sm.resolved, nil
+	}
+	return sm.resolve()
+}
+
+// resolve build an untyped StructMatcher, accessors are turned into paths of the fields
+func (sm StructMatcherOf[T]) resolve() (StructMatcher, error) {
+	builder := MatchStruct(sm.Matching).With(sm.Options...)
+	if len(sm.SkipFields) == 0 && len(sm.MatcherFields) == 0 {
+		return builder.build()
+	}
+
+	probe := new(T)
+	paths := make(map[fieldAddress]fieldPath)
+	d := newStructDiff(builder.matcher)
+	d.collectPaths(fieldPath{}, reflect.ValueOf(probe).Elem(), paths, make(map[reflect.Type]bool))
+	resolve := func(field FieldOf[T]) (string, error) {
+		path, err := resolveField(probe, field(probe), paths)
+		runtime.KeepAlive(probe)
+		return path, err
+	}
+
+	for i, field := range sm.SkipFields {
+		path, err := resolve(field)
+		if err != nil {
+			return builder.matcher, fmt.Errorf("StructMatcherOf: SkipFields[%d]: %v", i, err)
+		}
+		builder.Skip(path)
+	}
+	for i, fm := range sm.MatcherFields {
+		path, err := resolve(fm.Field)
+		if err != nil {
+			return builder.matcher, fmt.Errorf("StructMatcherOf: MatcherFields[%d]: %v", i, err)
+		}
+		builder.Field(path, fm.Matcher)
+	}
+	return builder.build()
+}
+
+// String return a string value of matching fields
+func (sm StructMatcherOf[T]) String() string {
+	return sm.matcher().String()
+}
+
+// Matches return true if the T or *T value matches the structure
+func (sm StructMatcherOf[T]) Matches(x interface{}) bool {
+	return sm.matcher().Matches(sm.value(x))
+}
+
+// Got implements gomock.GotFormatter, see StructMatcher.Got
+func (sm StructMatcherOf[T]) Got(x interface{}) string {
+	return sm.matcher().Got(sm.value(x))
+}
+
+// Mismatches return a list of fields which do not match the structure
+func (sm StructMatcherOf[T]) Mismatches(x interface{}) []Mismatch {
+	return sm.matcher().Mismatches(sm.value(x))
+}
+
+// matcher return the untyped matcher, it panics on invalid accessors
+func (sm StructMatcherOf[T]) matcher() StructMatcher {
+	matcher, err := sm.StructMatcher()
+	if err != nil {
+		panic(err)
+	}
+	return matcher
+}
+
+// value dereference a pointer to T
+func (sm StructMatcherOf[T]) value(x interface{}) interface{} {
+	if p, ok := x.(*T); ok && p != nil {
+		return *p
+	}
+	return x
+}
+
+// fieldAddress is an address of a field with its type, a structure and its first field
+// have the same address, so the type is needed to distinguish them
+type fieldAddress struct {
+	address uintptr
+	typ     reflect.Type
+}
+
+// collectPaths save paths of all fields of the value by their addresses, nil pointers to
+// structures are replaced with new structures, so accessors of nested fields don't panic
+func (d *structDiff) collectPaths(path fieldPath, v reflect.Value, paths map[fieldAddress]fieldPath, stack map[reflect.Type]bool) {
+	address := fieldAddress{address: v.UnsafeAddr(), typ: v.Type()}
+	if _, ok := paths[address]; !ok {
+		paths[address] = path
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := v.Type().Elem()
+		if elem.Kind() != reflect.Struct || stack[elem] {
+			return
+		}
+		v.Set(reflect.New(elem))
+		d.collectPaths(path, v.Elem(), paths, stack)
+	case reflect.Struct:
+		stack[v.Type()] = true
+		defer delete(stack, v.Type())
+		for _, field := range d.structFields(path, v.Type()) {
+			d.collectPaths(field.path, exported(v.Field(field.index)), paths, stack)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			d.collectPaths(path.index(strconv.Itoa(i)), v.Index(i), paths, stack)
+		}
+	}
+}
+
+// resolveField return a path of the field which the accessor has returned a pointer to
+func resolveField(probe, pointer interface{}, paths map[fieldAddress]fieldPath) (string, error) {
+	p := reflect.ValueOf(pointer)
+	if p.Kind() != reflect.Ptr || p.IsNil() {
+		return "", fmt.Errorf("the accessor should return a pointer to a field of %T, got %T", probe, pointer)
+	}
+	path, ok := paths[fieldAddress{address: p.Pointer(), typ: p.Type().Elem()}]
+	if !ok || len(path) == 0 {
+		return "", fmt.Errorf("the accessor should return a pointer to a field of %T", probe)
+	}
+	return path.String(), nil
+}
@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_StructMatcherOf(t *testing.T) {
+	Convey("Test typed StructMatcher", t, func() {
+		now := time.Now()
+		expected := TestNestedStruct{
+			Owner: &TestNestedItem{ID: 1, CreatedAt: now},
+			Pairs: [2]TestNestedItem{{ID: 2, CreatedAt: now}, {ID: 3, CreatedAt: now}},
+		}
+		actual := TestNestedStruct{
+			Owner: &TestNestedItem{ID: 1, CreatedAt: now.Add(time.Second)},
+			Pairs: [2]TestNestedItem{{ID: 2, CreatedAt: now}, {ID: 4, CreatedAt: now}},
+		}
+
+		Convey("Test accessors", func() {
+			matcher := StructMatcherOf[TestNestedStruct]{
+				Matching:   expected,
+				SkipFields: []FieldOf[TestNestedStruct]{func(s *TestNestedStruct) interface{} { return &s.Pairs[1].ID }},
+				MatcherFields: []FieldMatcherOf[TestNestedStruct]{
+					MatchField(func(s *TestNestedStruct) interface{} { return &s.Owner.CreatedAt }, TimeMatcher{now}),
+				},
+			}
+			untyped, err := matcher.StructMatcher()
+			So(err, ShouldBeNil)
+			So(untyped.SkipFields, ShouldResemble, []string{"Pairs[1].ID"})
+			So(untyped.MatcherFields, ShouldResemble, map[string]gomock.Matcher{"Owner.CreatedAt": TimeMatcher{now}})
+
+			var _ gomock.Matcher = matcher
+			So(matcher.Matches(actual), ShouldBeTrue)
+			So(matcher.Matches(&actual), ShouldBeTrue)
+			So(matcher.Matches(TestNestedItem{}), ShouldBeFalse)
+
+			actual.Pairs[0].ID = 5
+			So(matcher.Mismatches(actual), ShouldResemble, []Mismatch{
+				{Path: "Pairs[0].ID", Rule: RuleDeepEqual, Expected: int64(2), Actual: int64(5)},
+			})
+		})
+		Convey("Test embedded, unexported and tagged fields", func() {
+			matcher := StructMatcherOf[TestModel]{
+				SkipFields: []FieldOf[TestModel]{
+					func(m *TestModel) interface{} { return &m.CreatedAt },
+					func(m *TestModel) interface{} { return &m.TestBaseModel },
+					func(m *TestModel) interface{} { return &m.secret },
+					func(m *TestModel) interface{} { return &m.UpdatedAt },
+				},
+			}
+			untyped, err := matcher.StructMatcher()
+			So(err, ShouldBeNil)
+			So(untyped.SkipFields, ShouldResemble, []string{
+				"TestBaseModel.CreatedAt", "TestBaseModel", "secret", "testAudit.UpdatedAt",
+			})
+
+			tagged := StructMatcherOf[TestTaggedStruct]{
+				SkipFields: []FieldOf[TestTaggedStruct]{func(s *TestTaggedStruct) interface{} { return &s.UserName }},
+				Options:    []MatchOption{WithTagKey("json")},
+			}
+			untyped, err = tagged.StructMatcher()
+			So(err, ShouldBeNil)
+			So(untyped.SkipFields, ShouldResemble, []string{"user_name"})
+			So(untyped.TagKey, ShouldEqual, "json")
+		})
+		Convey("Test invalid accessors", func() {
+			other := &TestNestedItem{}
+			matcher := StructMatcherOf[TestNestedStruct]{
+				SkipFields: []FieldOf[TestNestedStruct]{func(s *TestNestedStruct) interface{} { return &other.ID }},
+			}
+			_, err := matcher.StructMatcher()
+			So(err.Error(), ShouldEqual, "StructMatcherOf: SkipFields[0]: the accessor should return a pointer to a field of *helpers.TestNestedStruct")
+			So(func() { matcher.Matches(actual) }, ShouldPanic)
+
+			matcher = StructMatcherOf[TestNestedStruct]{
+				MatcherFields: []FieldMatcherOf[TestNestedStruct]{
+					MatchField(func(s *TestNestedStruct) interface{} { return s.Owner.ID }, 1),
+				},
+			}
+			_, err = matcher.StructMatcher()
+			So(err.Error(), ShouldEqual, "StructMatcherOf: MatcherFields[0]: the accessor should return a pointer to a field of *helpers.TestNestedStruct, got int64")
+
+			So(func() { matcher.Build() }, ShouldPanic)
+			helper := &testHelper{}
+			matcher.BuildT(helper)
+			So(helper.message, ShouldEqual, err.Error())
+
+			options := StructMatcherOf[TestNestedStruct]{Options: []MatchOption{WithSkipFields("Unknown")}}
+			_, err = options.StructMatcher()
+			So(err.Error(), ShouldEqual, `StructMatcher: helpers.TestNestedStruct has no fields "Unknown"`)
+		})
+		Convey("Test accessors are resolved once by Build", func() {
+			calls := 0
+			matcher := StructMatcherOf[TestNestedStruct]{
+				Matching: expected,
+				SkipFields: []FieldOf[TestNestedStruct]{func(s *TestNestedStruct) interface{} {
+					calls++
+					return &s.Owner.CreatedAt
+				}},
+				MatcherFields: []FieldMatcherOf[TestNestedStruct]{
+					MatchField(func(s *TestNestedStruct) interface{} { return &s.Pairs[1].ID }, int64(4)),
+				},
+			}.BuildT(t)
+			So(calls, ShouldEqual, 1)
+
+			So(matcher.Matches(actual), ShouldBeTrue)
+			So(matcher.Got(actual), ShouldNotBeEmpty)
+			So(matcher.Mismatches(&actual), ShouldBeEmpty)
+			So(matcher.String(), ShouldStartWith, "StructMatcher to ")
+			So(calls, ShouldEqual, 1)
+		})
+	})
+}
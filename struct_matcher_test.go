@@ -1,6 +1,13 @@
 package helpers
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -141,3 +148,796 @@ func Test_StructMatcher(t *testing.T) {
 		})
 	})
 }
+
+func Test_StructMatcherMismatches(t *testing.T) {
+	var _ gomock.GotFormatter = StructMatcher{}
+
+	Convey("Test StructMatcher mismatch report", t, func() {
+		now := time.Now()
+		matching := TestStruct{
+			IntField:    1,
+			TimeField:   now,
+			StringField: "testString",
+		}
+
+		Convey("Test no mismatches", func() {
+			matcher := StructMatcher{Matching: matching}
+			So(matcher.Mismatches(matching), ShouldBeEmpty)
+			So(matcher.Got(matching), ShouldEqual, fmt.Sprintf("%v", matching))
+		})
+		Convey("Test wrong type mismatch", func() {
+			matcher := StructMatcher{Matching: matching}
+			mismatches := matcher.Mismatches("not a struct")
+			So(mismatches, ShouldHaveLength, 1)
+			So(mismatches[0].Rule, ShouldEqual, RuleType)
+			So(mismatches[0].Path, ShouldBeEmpty)
+		})
+		Convey("Test DeepEqual and MatcherFields mismatches", func() {
+			matcher := StructMatcher{
+				Matching:      matching,
+				SkipFields:    []string{"IntField"},
+				MatcherFields: map[string]gomock.Matcher{"TimeField": TimeMatcher{Matching: now.Add(time.Hour)}},
+			}
+			passed := matching
+			passed.IntField = 2
+			passed.StringField = "anotherString"
+
+			mismatches := matcher.Mismatches(passed)
+			So(mismatches, ShouldResemble, []Mismatch{
+				{Path: "TimeField", Rule: RuleMatcherFields, Expected: matcher.MatcherFields["TimeField"], Actual: now},
+				{Path: "StringField", Rule: RuleDeepEqual, Expected: "testString", Actual: "anotherString"},
+			})
+			got := matcher.Got(passed)
+			So(got, ShouldContainSubstring, "mismatched fields:")
+			So(got, ShouldContainSubstring, "StringField [DeepEqual]: expected testString, got anotherString")
+			So(got, ShouldContainSubstring, "TimeField [MatcherFields]: expected match to time after")
+			So(got, ShouldNotContainSubstring, "IntField")
+		})
+	})
+}
+
+type TestNestedItem struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+type TestNestedStruct struct {
+	Owner *TestNestedItem
+	Items []TestNestedItem
+	Pairs [2]TestNestedItem
+	Meta  map[string]interface{}
+}
+
+func Test_StructMatcherNested(t *testing.T) {
+	Convey("Test StructMatcher with nested fields", t, func() {
+		matching := TestNestedStruct{
+			Owner: &TestNestedItem{ID: 1, CreatedAt: time.Now()},
+			Items: []TestNestedItem{{ID: 2, CreatedAt: time.Now()}, {ID: 3, CreatedAt: time.Now()}},
+			Pairs: [2]TestNestedItem{{ID: 4}, {ID: 5}},
+			Meta:  map[string]interface{}{"key": time.Now(), "name": "test"},
+		}
+		passed := TestNestedStruct{
+			Owner: &TestNestedItem{ID: 1, CreatedAt: time.Now()},
+			Items: []TestNestedItem{{ID: 2, CreatedAt: time.Now()}, {ID: 3, CreatedAt: time.Now()}},
+			Pairs: [2]TestNestedItem{{ID: 4, CreatedAt: time.Now()}, {ID: 5, CreatedAt: time.Now()}},
+			Meta:  map[string]interface{}{"key": time.Now(), "name": "test"},
+		}
+
+		Convey("Test nested time fields differ", func() {
+			matcher := StructMatcher{Matching: matching}
+			So(matcher.Matches(passed), ShouldBeFalse)
+			paths := make([]string, 0)
+			for _, m := range matcher.Mismatches(passed) {
+				paths = append(paths, m.Path)
+			}
+			So(paths, ShouldResemble, []string{
+				"Owner.CreatedAt", "Items[0].CreatedAt", "Items[1].CreatedAt",
+				"Pairs[0].CreatedAt", "Pairs[1].CreatedAt", "Meta[key]",
+			})
+		})
+		Convey("Test nested skip fields", func() {
+			matcher := StructMatcher{
+				Matching:   matching,
+				SkipFields: []string{"Owner.CreatedAt", "Items[*].CreatedAt", "Pairs[*].CreatedAt", "Meta[key]"},
+			}
+			So(matcher.Matches(passed), ShouldBeTrue)
+		})
+		Convey("Test nested matcher fields", func() {
+			timeMatcher := TimeMatcher{Matching: matching.Owner.CreatedAt}
+			matcher := StructMatcher{
+				Matching: matching,
+				MatcherFields: map[string]gomock.Matcher{
+					"Owner.CreatedAt":    timeMatcher,
+					"Items[*].CreatedAt": timeMatcher,
+					"Pairs[0].CreatedAt": timeMatcher,
+					"Pairs[1]":           gomock.Any(),
+					"Meta[key]":          timeMatcher,
+				},
+			}
+			So(matcher.Matches(passed), ShouldBeTrue)
+
+			passed.Items[1].ID = 10
+			So(matcher.Mismatches(passed), ShouldResemble, []Mismatch{
+				{Path: "Items[1].ID", Rule: RuleDeepEqual, Expected: int64(3), Actual: int64(10)},
+			})
+		})
+		Convey("Test missed and extra map keys", func() {
+			passed.Meta = map[string]interface{}{"name": "test", "extra": 1}
+			matcher := StructMatcher{
+				Matching:   matching,
+				SkipFields: []string{"Owner", "Items", "Pairs"},
+			}
+			So(matcher.Mismatches(passed), ShouldResemble, []Mismatch{
+				{Path: "Meta[key]", Rule: RuleDeepEqual, Expected: matching.Meta["key"], Actual: nil},
+				{Path: "Meta[extra]", Rule: RuleDeepEqual, Expected: nil, Actual: 1},
+			})
+		})
+		Convey("Test different slice length", func() {
+			passed.Items = passed.Items[:1]
+			matcher := StructMatcher{
+				Matching:   matching,
+				SkipFields: []string{"Owner", "Pairs", "Meta", "Items[*].CreatedAt"},
+			}
+			mismatches := matcher.Mismatches(passed)
+			So(mismatches, ShouldHaveLength, 1)
+			So(mismatches[0].Path, ShouldEqual, "Items")
+		})
+		Convey("Test matcher fields behind a shared pointer", func() {
+			passed.Owner = matching.Owner
+			matcher := StructMatcher{
+				Matching:      matching,
+				SkipFields:    []string{"Items", "Pairs", "Meta"},
+				MatcherFields: map[string]gomock.Matcher{"Owner.CreatedAt": gomock.Nil()},
+			}
+			So(matcher.Mismatches(passed), ShouldResemble, []Mismatch{
+				{Path: "Owner.CreatedAt", Rule: RuleMatcherFields, Expected: gomock.Nil(), Actual: matching.Owner.CreatedAt},
+			})
+
+			matcher.MatcherFields = nil
+			So(matcher.Matches(passed), ShouldBeTrue)
+		})
+	})
+}
+
+type TestBaseModel struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+type testAudit struct {
+	UpdatedAt time.Time
+}
+
+type TestModel struct {
+	tableName struct{} `sql:"models"`
+	TestBaseModel
+	*testAudit
+	Name    string
+	secret  string
+	Created time.Time
+}
+
+type TestShadowingModel struct {
+	TestBaseModel
+	CreatedAt string
+}
+
+func Test_StructMatcherUnexportedAndEmbedded(t *testing.T) {
+	Convey("Test StructMatcher with unexported and embedded fields", t, func() {
+		matching := TestModel{
+			TestBaseModel: TestBaseModel{ID: 1, CreatedAt: time.Now()},
+			testAudit:     &testAudit{UpdatedAt: time.Now()},
+			Name:          "model",
+			secret:        "secret",
+		}
+		passed := TestModel{
+			TestBaseModel: TestBaseModel{ID: 1, CreatedAt: time.Now()},
+			testAudit:     &testAudit{UpdatedAt: time.Now()},
+			Name:          "model",
+			secret:        "another secret",
+		}
+
+		Convey("Test unexported fields are compared", func() {
+			matcher := StructMatcher{
+				Matching:   matching,
+				SkipFields: []string{"CreatedAt", "UpdatedAt"},
+			}
+			So(matcher.Mismatches(passed), ShouldResemble, []Mismatch{
+				{Path: "secret", Rule: RuleDeepEqual, Expected: "secret", Actual: "another secret"},
+			})
+		})
+		Convey("Test unexported fields are skipped by the option", func() {
+			matcher := StructMatcher{
+				Matching:       matching,
+				SkipFields:     []string{"CreatedAt", "UpdatedAt"},
+				SkipUnexported: true,
+			}
+			So(matcher.Matches(passed), ShouldBeTrue)
+		})
+		Convey("Test unexported fields with matchers", func() {
+			matcher := StructMatcher{
+				Matching:      matching,
+				SkipFields:    []string{"CreatedAt", "UpdatedAt"},
+				MatcherFields: map[string]gomock.Matcher{"secret": gomock.Eq("another secret")},
+			}
+			So(matcher.Matches(passed), ShouldBeTrue)
+		})
+		Convey("Test promoted and full paths of embedded fields", func() {
+			matcher := StructMatcher{
+				Matching:   matching,
+				SkipFields: []string{"secret", "TestBaseModel.CreatedAt", "UpdatedAt"},
+			}
+			So(matcher.Matches(passed), ShouldBeTrue)
+
+			matcher.SkipFields = []string{"secret", "CreatedAt", "testAudit.UpdatedAt"}
+			So(matcher.Matches(passed), ShouldBeTrue)
+
+			matcher.SkipFields = []string{"secret", "UpdatedAt"}
+			mismatches := matcher.Mismatches(passed)
+			So(mismatches, ShouldHaveLength, 1)
+			So(mismatches[0].Path, ShouldEqual, "TestBaseModel.CreatedAt")
+		})
+		Convey("Test shadowed fields are not promoted", func() {
+			now := time.Now()
+			shadowing := TestShadowingModel{TestBaseModel: TestBaseModel{ID: 1, CreatedAt: now}, CreatedAt: "now"}
+			matcher := StructMatcher{
+				Matching:   shadowing,
+				SkipFields: []string{"CreatedAt"},
+			}
+			passed := shadowing
+			passed.TestBaseModel.CreatedAt = now.Add(time.Second)
+			So(matcher.Matches(passed), ShouldBeFalse)
+
+			matcher.SkipFields = []string{"TestBaseModel.CreatedAt"}
+			So(matcher.Matches(passed), ShouldBeTrue)
+		})
+	})
+}
+
+func Test_StructMatcherPartial(t *testing.T) {
+	Convey("Test StructMatcher in partial mode", t, func() {
+		now := time.Now()
+		passed := TestNestedStruct{
+			Owner: &TestNestedItem{ID: 1, CreatedAt: now},
+			Items: []TestNestedItem{{ID: 2, CreatedAt: now}, {ID: 3, CreatedAt: now}},
+			Meta:  map[string]interface{}{"key": "value"},
+		}
+
+		Convey("Test a map of raw values and matchers", func() {
+			matcher := StructMatcher{
+				Matching: map[string]interface{}{
+					"Owner.ID":        1,
+					"Owner.CreatedAt": TimeMatcher{Matching: now.Add(-time.Second)},
+					"Items[1].ID":     int64(3),
+					"Meta[key]":       "value",
+				},
+			}
+			So(matcher.Matches(passed), ShouldBeTrue)
+			So(matcher.Matches(&passed), ShouldBeTrue)
+		})
+		Convey("Test a map with mismatched and missed fields", func() {
+			matcher := StructMatcher{
+				Matching: map[string]interface{}{
+					"Owner.ID":      2,
+					"Items[*].ID":   gomock.Not(int64(3)),
+					"Items[5].ID":   int64(5),
+					"Meta[missing]": gomock.Nil(),
+					"Meta[another]": "value",
+				},
+			}
+			So(matcher.Mismatches(passed), ShouldResemble, []Mismatch{
+				{Path: "Owner.ID", Rule: RuleDeepEqual, Expected: 2, Actual: int64(1)},
+				{Path: "Items[1].ID", Rule: RuleMatcherFields, Expected: gomock.Not(int64(3)), Actual: int64(3)},
+				{Path: "Items[5].ID", Rule: RuleDeepEqual, Expected: int64(5), Actual: nil},
+				{Path: "Meta[another]", Rule: RuleDeepEqual, Expected: "value", Actual: nil},
+			})
+		})
+		Convey("Test a map with lossy conversions of raw values", func() {
+			type counters struct {
+				Count int8
+				Size  uint8
+				Total int
+			}
+			passed := counters{Count: 1, Size: 255, Total: 1}
+			So(StructMatcher{Matching: map[string]interface{}{
+				"Count": 1, "Size": 255.0, "Total": int8(1),
+			}}.Matches(passed), ShouldBeTrue)
+
+			for _, fields := range []map[string]interface{}{
+				{"Count": 257},
+				{"Total": 1.5},
+				{"Size": -1},
+			} {
+				So(StructMatcher{Matching: fields}.Matches(passed), ShouldBeFalse)
+			}
+
+			// map keys are converted the same way
+			type rates struct{ Rates map[int64]string }
+			type smallRates struct{ Rates map[int8]string }
+			matcher := StructMatcher{Matching: rates{Rates: map[int64]string{1: "one"}}, CrossType: true}
+			So(matcher.Matches(smallRates{Rates: map[int8]string{1: "one"}}), ShouldBeTrue)
+			matcher.Matching = rates{Rates: map[int64]string{257: "one"}}
+			So(matcher.Matches(smallRates{Rates: map[int8]string{1: "one"}}), ShouldBeFalse)
+		})
+		Convey("Test a map with a wrong type", func() {
+			matcher := StructMatcher{Matching: map[string]interface{}{"ID": 1}}
+			So(matcher.Matches("not a struct"), ShouldBeFalse)
+		})
+		Convey("Test non-zero fields of a struct", func() {
+			matcher := StructMatcher{
+				Matching: TestNestedStruct{
+					Owner: &TestNestedItem{ID: 1},
+					Items: []TestNestedItem{{ID: 2}, {ID: 3}},
+				},
+				Partial: true,
+			}
+			So(matcher.Matches(passed), ShouldBeTrue)
+
+			passed.Items[0].ID = 5
+			So(matcher.Mismatches(passed), ShouldResemble, []Mismatch{
+				{Path: "Items[0].ID", Rule: RuleDeepEqual, Expected: int64(2), Actual: int64(5)},
+			})
+		})
+		Convey("Test non-zero fields of a struct with matcher of a zero field", func() {
+			matcher := StructMatcher{
+				Matching:      TestStruct{IntField: 1},
+				MatcherFields: map[string]gomock.Matcher{"TimeField": TimeMatcher{Matching: now}},
+				Partial:       true,
+			}
+			So(matcher.Matches(TestStruct{IntField: 1, StringField: "test", TimeField: now}), ShouldBeTrue)
+			So(matcher.Matches(TestStruct{IntField: 1, StringField: "test"}), ShouldBeFalse)
+		})
+	})
+}
+
+type TestTaggedBase struct {
+	ID        int64     `json:"id" sql:"id,pk"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TestTaggedStruct struct {
+	tableName struct{} `sql:"tagged"`
+	TestTaggedBase
+	UserName string            `json:"user_name,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Password string            `json:"-"`
+	Meta     map[string]string `json:"meta" sql:"-"`
+	private  string
+}
+
+func Test_StructMatcherTags(t *testing.T) {
+	Convey("Test StructMatcher with tag names", t, func() {
+		now := time.Now()
+		matching := TestTaggedStruct{
+			TestTaggedBase: TestTaggedBase{ID: 1, CreatedAt: now},
+			UserName:       "user",
+			Password:       "secret",
+			Meta:           map[string]string{"key": "value"},
+			private:        "private",
+		}
+		passed := matching
+		passed.CreatedAt = now.Add(time.Second)
+		passed.Password = "another secret"
+		passed.Tags = []string{}
+		passed.private = "another private"
+
+		Convey("Test json names", func() {
+			matcher := StructMatcher{Matching: matching, TagKey: "json"}
+			So(matcher.Mismatches(passed), ShouldResemble, []Mismatch{
+				{Path: "TestTaggedBase.created_at", Rule: RuleDeepEqual, Expected: now, Actual: passed.CreatedAt},
+			})
+
+			matcher.SkipFields = []string{"created_at"}
+			So(matcher.Matches(passed), ShouldBeTrue)
+
+			matcher.SkipFields = []string{"TestTaggedBase.created_at"}
+			So(matcher.Matches(passed), ShouldBeTrue)
+
+			matcher.SkipFields = []string{"CreatedAt"}
+			So(matcher.Matches(passed), ShouldBeFalse)
+		})
+		Convey("Test json names with matchers and partial map", func() {
+			matcher := StructMatcher{
+				Matching:      matching,
+				TagKey:        "json",
+				MatcherFields: map[string]gomock.Matcher{"created_at": TimeMatcher{Matching: now}},
+			}
+			So(matcher.Matches(passed), ShouldBeTrue)
+
+			matcher = StructMatcher{
+				Matching: map[string]interface{}{"id": 1, "meta[key]": "value", "user_name": "user"},
+				TagKey:   "json",
+			}
+			So(matcher.Matches(passed), ShouldBeTrue)
+		})
+		Convey("Test omitempty fields", func() {
+			matcher := StructMatcher{Matching: matching, TagKey: "json", SkipFields: []string{"created_at"}}
+			passed.Tags = []string{"tag"}
+			So(matcher.Mismatches(passed), ShouldResemble, []Mismatch{
+				{Path: "tags", Rule: RuleDeepEqual, Expected: []string(nil), Actual: []string{"tag"}},
+			})
+		})
+		Convey("Test go-pg names", func() {
+			matcher := StructMatcher{
+				Matching:   matching,
+				TagKey:     "sql",
+				SkipFields: []string{"created_at", "password"},
+			}
+			passed.Meta = nil
+			passed.Tags = nil
+			So(matcher.Matches(passed), ShouldBeTrue)
+
+			passed.UserName = "another user"
+			So(matcher.Mismatches(passed), ShouldResemble, []Mismatch{
+				{Path: "user_name", Rule: RuleDeepEqual, Expected: "user", Actual: "another user"},
+			})
+		})
+	})
+}
+
+func TestUnderscore(t *testing.T) {
+	cases := map[string]string{
+		"ID":        "id",
+		"UserID":    "user_id",
+		"CreatedAt": "created_at",
+		"HTTPCode":  "http_code",
+		"name":      "name",
+	}
+	for name, expected := range cases {
+		if got := underscore(name); got != expected {
+			t.Errorf("expected '%s', got '%s'", expected, got)
+		}
+	}
+}
+
+type TestISOTime time.Time
+
+type TestCreateRequest struct {
+	Name     *string           `json:"name"`
+	Age      int32             `json:"age"`
+	Birthday TestISOTime       `json:"birthday"`
+	Tags     []string          `json:"tags"`
+	Meta     map[string]string `json:"meta"`
+	Token    string            `json:"token"`
+}
+
+type TestUserModel struct {
+	TestBaseModel
+	Name     string
+	Age      int64
+	Birthday time.Time
+	Tags     []string
+	Meta     map[string]string
+	Active   bool
+}
+
+func Test_StructMatcherCrossType(t *testing.T) {
+	Convey("Test StructMatcher for different types", t, func() {
+		name := "user"
+		birthday := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		request := TestCreateRequest{
+			Name:     &name,
+			Age:      20,
+			Birthday: TestISOTime(birthday),
+			Tags:     []string{"tag"},
+			Meta:     map[string]string{"key": "value"},
+			Token:    "token",
+		}
+		model := TestUserModel{
+			TestBaseModel: TestBaseModel{ID: 1, CreatedAt: time.Now()},
+			Name:          name,
+			Age:           20,
+			Birthday:      birthday,
+			Tags:          []string{"tag"},
+			Meta:          map[string]string{"key": "value"},
+			Active:        true,
+		}
+
+		Convey("Test different types without the cross-type mode", func() {
+			matcher := StructMatcher{Matching: request}
+			So(matcher.Mismatches(model), ShouldResemble, []Mismatch{
+				{Rule: RuleType, Expected: reflect.TypeOf(request), Actual: reflect.TypeOf(model)},
+			})
+		})
+		Convey("Test missed and extra fields", func() {
+			matcher := StructMatcher{Matching: request, CrossType: true}
+			So(matcher.Mismatches(model), ShouldResemble, []Mismatch{
+				{Path: "Token", Rule: RuleMissingField, Expected: "token", Actual: nil},
+				{Path: "Active", Rule: RuleExtraField, Expected: nil, Actual: true},
+				{Path: "TestBaseModel.ID", Rule: RuleExtraField, Expected: nil, Actual: int64(1)},
+				{Path: "TestBaseModel.CreatedAt", Rule: RuleExtraField, Expected: nil, Actual: model.CreatedAt},
+			})
+		})
+		Convey("Test converted fields", func() {
+			matcher := StructMatcher{
+				Matching:   request,
+				CrossType:  true,
+				SkipFields: []string{"Token", "Active", "ID", "CreatedAt"},
+			}
+			So(matcher.Matches(model), ShouldBeTrue)
+			So(matcher.Matches(&model), ShouldBeTrue)
+			So(StructMatcher{Matching: &request, CrossType: true, SkipFields: matcher.SkipFields}.Matches(model), ShouldBeTrue)
+			So(matcher.Matches(TestCreateRequest{}), ShouldBeFalse)
+			So(matcher.Mismatches(1), ShouldResemble, []Mismatch{
+				{Rule: RuleType, Expected: reflect.TypeOf(request), Actual: reflect.TypeOf(1)},
+			})
+
+			model.Age = 21
+			model.Name = "another user"
+			So(matcher.Mismatches(model), ShouldResemble, []Mismatch{
+				{Path: "Name", Rule: RuleDeepEqual, Expected: "user", Actual: "another user"},
+				{Path: "Age", Rule: RuleDeepEqual, Expected: int32(20), Actual: int64(21)},
+			})
+		})
+		Convey("Test lossy conversions", func() {
+			type dto struct {
+				Count int8
+				Price int
+				Rate  float32
+			}
+			type model struct {
+				Count int64
+				Price float64
+				Rate  float64
+			}
+			matcher := StructMatcher{Matching: dto{Count: 1, Price: 3, Rate: 0.5}, CrossType: true}
+			So(matcher.Matches(model{Count: 1, Price: 3, Rate: 0.5}), ShouldBeTrue)
+			So(matcher.Mismatches(model{Count: 257, Price: 3.99, Rate: 0.1}), ShouldResemble, []Mismatch{
+				{Path: "Count", Rule: RuleDeepEqual, Expected: int8(1), Actual: int64(257)},
+				{Path: "Price", Rule: RuleDeepEqual, Expected: 3, Actual: 3.99},
+				{Path: "Rate", Rule: RuleDeepEqual, Expected: float32(0.5), Actual: 0.1},
+			})
+
+			// float32 0.1 doesn't fit float64 0.1 exactly, so they are compared as float64
+			matcher = StructMatcher{Matching: dto{Count: 1, Price: 3, Rate: 0.1}, CrossType: true, FloatEpsilon: 0.0001}
+			So(matcher.Matches(model{Count: 1, Price: 3, Rate: 0.1}), ShouldBeTrue)
+		})
+		Convey("Test mutually embedded types", func() {
+			type dto struct {
+				Name string
+				Code string
+			}
+			matcher := StructMatcher{Matching: dto{Name: "a", Code: "b"}, CrossType: true}
+			// the type embedded again is a plain field
+			So(matcher.Mismatches(TestCycleA{Name: "a", TestCycleB: &TestCycleB{Code: "b"}}), ShouldResemble, []Mismatch{
+				{Path: "TestCycleB.TestCycleA", Rule: RuleExtraField, Expected: nil, Actual: (*TestCycleA)(nil)},
+			})
+
+			matcher.SkipFields = []string{"TestCycleB.TestCycleA"}
+			So(matcher.Matches(TestCycleA{Name: "a", TestCycleB: &TestCycleB{Code: "b"}}), ShouldBeTrue)
+			So(matcher.Mismatches(TestCycleA{Name: "a"}), ShouldResemble, []Mismatch{
+				{Path: "Code", Rule: RuleDeepEqual, Expected: "b", Actual: ""},
+			})
+		})
+		Convey("Test incompatible field types", func() {
+			matcher := StructMatcher{
+				Matching:  struct{ Name int }{Name: 1},
+				CrossType: true,
+			}
+			So(matcher.Mismatches(struct{ Name string }{Name: "1"}), ShouldResemble, []Mismatch{
+				{Path: "Name", Rule: RuleType, Expected: reflect.TypeOf(1), Actual: reflect.TypeOf("")},
+			})
+		})
+		Convey("Test by tag names", func() {
+			type dto struct {
+				UserName string `json:"user_name"`
+			}
+			type model struct {
+				Name string `json:"user_name"`
+			}
+			matcher := StructMatcher{Matching: dto{UserName: "user"}, CrossType: true, TagKey: "json"}
+			So(matcher.Matches(model{Name: "user"}), ShouldBeTrue)
+			So(matcher.Matches(model{Name: "another user"}), ShouldBeFalse)
+		})
+	})
+}
+
+type TestGoldenUser struct {
+	ID        int
+	Name      string
+	Tags      []string
+	CreatedAt time.Time
+}
+
+func Test_StructMatcherGolden(t *testing.T) {
+	Convey("Test StructMatcher with golden files", t, func() {
+		user := TestGoldenUser{
+			ID:        1,
+			Name:      "user",
+			Tags:      []string{"admin", "owner"},
+			CreatedAt: time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC),
+		}
+
+		Convey("Test matching golden file", func() {
+			matcher := StructMatcher{Golden: "struct_matcher"}
+			So(matcher.String(), ShouldEqual, "StructMatcher to golden file testdata/struct_matcher.golden.json")
+			So(matcher.Matches(user), ShouldBeTrue)
+			So(matcher.Matches(&user), ShouldBeTrue)
+
+			user.Name = "another user"
+			So(matcher.Mismatches(user), ShouldResemble, []Mismatch{
+				{Path: "Name", Rule: RuleDeepEqual, Expected: "user", Actual: "another user"},
+			})
+		})
+		Convey("Test skipped and matcher fields", func() {
+			user.ID = 2
+			user.CreatedAt = time.Now()
+			matcher := StructMatcher{
+				Golden:        "struct_matcher",
+				SkipFields:    []string{"ID"},
+				MatcherFields: map[string]gomock.Matcher{"CreatedAt": TimeMatcher{user.CreatedAt.Add(-time.Second)}},
+			}
+			So(matcher.Matches(user), ShouldBeTrue)
+		})
+		Convey("Test missing golden file", func() {
+			matcher := StructMatcher{Golden: "missing"}
+			mismatches := matcher.Mismatches(user)
+			So(mismatches, ShouldHaveLength, 1)
+			So(mismatches[0].Rule, ShouldEqual, RuleGolden)
+			So(mismatches[0].Expected, ShouldEqual, "testdata/missing.golden.json")
+		})
+		Convey("Test unknown fields in golden file", func() {
+			matcher := StructMatcher{Golden: "struct_matcher"}
+			So(matcher.Matches(struct{ ID int }{ID: 1}), ShouldBeFalse)
+		})
+		Convey("Test not a structure", func() {
+			matcher := StructMatcher{Golden: "struct_matcher"}
+			So(matcher.Matches(1), ShouldBeFalse)
+		})
+	})
+}
+
+func Test_StructMatcherGoldenUpdate(t *testing.T) {
+	dir := t.TempDir()
+
+	Convey("Test updating golden files", t, func() {
+		t.Setenv(UpdateGoldenEnv, "1")
+		user := TestGoldenUser{ID: 1, Name: "updated user"}
+
+		Convey("Test writing golden file once", func() {
+			matcher := StructMatcher{Golden: filepath.Join(dir, "user")}
+			So(matcher.String(), ShouldEqual, "StructMatcher to golden file "+filepath.Join(dir, "user.golden.json"))
+			So(matcher.Matches(user), ShouldBeTrue)
+			So(matcher.Matches(&user), ShouldBeTrue)
+
+			// the golden file is kept, so another call of the update mode is still checked
+			another := user
+			another.Name = "another user"
+			So(matcher.Mismatches(another), ShouldResemble, []Mismatch{
+				{Path: "Name", Rule: RuleDeepEqual, Expected: "updated user", Actual: "another user"},
+			})
+
+			t.Setenv(UpdateGoldenEnv, "")
+			So(matcher.Matches(user), ShouldBeTrue)
+			So(matcher.Matches(another), ShouldBeFalse)
+		})
+		Convey("Test writing golden file by the flag", func() {
+			t.Setenv(UpdateGoldenEnv, "")
+			RegisterUpdateFlag()
+			RegisterUpdateFlag()
+			So(flag.Set("update", "true"), ShouldBeNil)
+			defer func() { UpdateGolden = false }()
+			So(UpdateGolden, ShouldBeTrue)
+
+			matcher := StructMatcher{Golden: filepath.Join(dir, "flag")}
+			So(matcher.Matches(user), ShouldBeTrue)
+			data, err := ioutil.ReadFile(matcher.GoldenPath())
+			So(err, ShouldBeNil)
+			So(string(data), ShouldContainSubstring, `"Name": "updated user"`)
+		})
+		Convey("Test failed writing", func() {
+			file := filepath.Join(dir, "file")
+			So(ioutil.WriteFile(file, nil, 0644), ShouldBeNil)
+			matcher := StructMatcher{Golden: filepath.Join(file, "user")}
+			mismatches := matcher.Mismatches(user)
+			So(mismatches, ShouldHaveLength, 1)
+			So(mismatches[0].Rule, ShouldEqual, RuleGolden)
+		})
+	})
+}
+
+type TestEqualityStruct struct {
+	Items  []int64
+	Meta   map[string]string
+	Score  float64
+	Rating float32
+	Users  []TestUser
+	Code   TestCode
+}
+
+type TestUser struct {
+	ID   int
+	Name string
+}
+
+type TestCode struct {
+	Value string
+}
+
+func Test_StructMatcherEquality(t *testing.T) {
+	Convey("Test StructMatcher equality options", t, func() {
+		expected := TestEqualityStruct{
+			Score:  0.3,
+			Rating: 4.5,
+			Users:  []TestUser{{ID: 1, Name: "first"}, {ID: 2, Name: "second"}},
+			Code:   TestCode{Value: "ABC"},
+		}
+		actual := expected
+		actual.Items = []int64{}
+		actual.Meta = map[string]string{}
+		actual.Users = []TestUser{{ID: 1, Name: "first"}, {ID: 2, Name: "second"}}
+
+		Convey("Test nil equals empty", func() {
+			So(StructMatcher{Matching: expected}.Matches(actual), ShouldBeFalse)
+			So(StructMatcher{Matching: expected, NilEqualsEmpty: true}.Matches(actual), ShouldBeTrue)
+
+			actual.Items = []int64{1}
+			So(StructMatcher{Matching: expected, NilEqualsEmpty: true}.Mismatches(actual), ShouldResemble, []Mismatch{
+				{Path: "Items", Rule: RuleDeepEqual, Expected: []int64(nil), Actual: []int64{1}},
+			})
+		})
+		Convey("Test float epsilon", func() {
+			actual.Items, actual.Meta = nil, nil
+			actual.Score = 0.1 + 0.2
+			actual.Rating = 4.5001
+			So(StructMatcher{Matching: expected}.Matches(actual), ShouldBeFalse)
+			So(StructMatcher{Matching: expected, FloatEpsilon: 0.001}.Matches(actual), ShouldBeTrue)
+
+			actual.Score = 0.31
+			So(StructMatcher{Matching: expected, FloatEpsilon: 0.001}.Mismatches(actual), ShouldResemble, []Mismatch{
+				{Path: "Score", Rule: RuleDeepEqual, Expected: 0.3, Actual: 0.31},
+			})
+		})
+		Convey("Test NaN", func() {
+			actual.Items, actual.Meta = nil, nil
+			expected.Score, actual.Score = math.NaN(), math.NaN()
+			So(StructMatcher{Matching: expected}.Matches(actual), ShouldBeFalse)
+			So(StructMatcher{Matching: expected, NaNEqual: true}.Matches(actual), ShouldBeTrue)
+
+			actual.Score = 1
+			So(StructMatcher{Matching: expected, NaNEqual: true}.Matches(actual), ShouldBeFalse)
+		})
+		Convey("Test unordered slices", func() {
+			actual.Items, actual.Meta = nil, nil
+			actual.Users = []TestUser{{ID: 2, Name: "second"}, {ID: 1, Name: "first"}}
+			So(StructMatcher{Matching: expected}.Matches(actual), ShouldBeFalse)
+			So(StructMatcher{Matching: expected, UnorderedSlices: true}.Matches(actual), ShouldBeTrue)
+			So(StructMatcher{
+				Matching:        expected,
+				UnorderedSlices: true,
+				SkipFields:      []string{"Users[*].ID"},
+			}.Matches(actual), ShouldBeTrue)
+
+			actual.Users = []TestUser{{ID: 2, Name: "second"}, {ID: 1, Name: "changed"}}
+			So(StructMatcher{Matching: expected, UnorderedSlices: true}.Mismatches(actual), ShouldResemble, []Mismatch{
+				{Path: "Users[0].Name", Rule: RuleDeepEqual, Expected: "first", Actual: "changed"},
+			})
+
+			actual.Users = []TestUser{{ID: 2, Name: "second"}, {ID: 1, Name: "first"}, {ID: 3, Name: "third"}}
+			So(StructMatcher{Matching: expected, UnorderedSlices: true}.Mismatches(actual), ShouldResemble, []Mismatch{
+				{Path: "Users[2]", Rule: RuleDeepEqual, Expected: nil, Actual: TestUser{ID: 3, Name: "third"}},
+			})
+
+			actual.Users = []TestUser{{ID: 2, Name: "second"}}
+			So(StructMatcher{Matching: expected, UnorderedSlices: true}.Mismatches(actual), ShouldResemble, []Mismatch{
+				{Path: "Users[0]", Rule: RuleDeepEqual, Expected: TestUser{ID: 1, Name: "first"}, Actual: nil},
+			})
+		})
+		Convey("Test custom comparers", func() {
+			actual.Items, actual.Meta = nil, nil
+			actual.Code = TestCode{Value: "abc"}
+			comparers := map[reflect.Type]Comparer{
+				reflect.TypeOf(TestCode{}): func(expected, actual interface{}) bool {
+					return strings.EqualFold(expected.(TestCode).Value, actual.(TestCode).Value)
+				},
+			}
+			So(StructMatcher{Matching: expected}.Matches(actual), ShouldBeFalse)
+			So(StructMatcher{Matching: expected, Comparers: comparers}.Matches(actual), ShouldBeTrue)
+
+			actual.Code = TestCode{Value: "abd"}
+			So(StructMatcher{Matching: expected, Comparers: comparers}.Mismatches(actual), ShouldResemble, []Mismatch{
+				{Path: "Code", Rule: RuleComparer, Expected: TestCode{Value: "ABC"}, Actual: TestCode{Value: "abd"}},
+			})
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+)
+
+// fieldTag describes how a struct field is named by a struct tag
+type fieldTag struct {
+	name      string
+	skip      bool // the field is excluded by "-"
+	omitEmpty bool // the field is omitted when it's empty
+	inline    bool // an embedded struct without a name, its fields are promoted
+}
+
+// parseFieldTag return a name of the field by the tag key, untagged fields are named
+// the same way as json, go-pg (pg, sql) and sqlx (db) packages do it
+func parseFieldTag(field reflect.StructField, key string) fieldTag {
+	tag := field.Tag.Get(key)
+	if tag == "-" {
+		return fieldTag{skip: true}
+	}
+	name := tag
+	options := ""
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		name, options = tag[:i], tag[i:]
+	}
+
+	result := fieldTag{name: name, omitEmpty: strings.Contains(options+",", ",omitempty,")}
+	if name != "" {
+		return result
+	}
+	if field.Anonymous {
+		result.inline = indirectType(field.Type).Kind() == reflect.Struct
+	}
+	switch key {
+	case "pg", "sql":
+		result.name = underscore(field.Name)
+	case "db":
+		result.name = strings.ToLower(field.Name)
+	default:
+		result.name = field.Name
+	}
+	return result
+}
+
+// underscore convert a CamelCase name to the snake_case one as go-pg does it
+func underscore(s string) string {
+	r := make([]byte, 0, len(s)+5)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+			if i > 0 && i+1 < len(s) && (isLower(s[i-1]) || isLower(s[i+1])) {
+				r = append(r, '_')
+			}
+		}
+		r = append(r, c)
+	}
+	return string(r)
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+// indirectType return a type which the pointer type points to
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isEmptyValue return true for values which are omitted by the "omitempty" option
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package helpers
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/astota/go-helperz/types"
+	"github.com/golang/mock/gomock"
+)
+
+func TestTimeMatchers(t *testing.T) {
+	now := time.Now()
+	moscow := time.FixedZone("MSK", 3*60*60)
+	var nilTime *time.Time
+
+	cases := []struct {
+		name    string
+		matcher gomock.Matcher
+		value   interface{}
+		matches bool
+	}{
+		{"after", TimeMatcher{Matching: now}, now.Add(time.Second), true},
+		{"after mismatch", TimeMatcher{Matching: now}, now.Add(-time.Second), false},
+		{"before", TimeBeforeMatcher{Matching: now}, now.Add(-time.Second), true},
+		{"before equal", TimeBeforeMatcher{Matching: now}, now, true},
+		{"before pointer", TimeBeforeMatcher{Matching: &now}, now.Add(-time.Second), true},
+		{"before mismatch", TimeBeforeMatcher{Matching: now}, now.Add(time.Second), false},
+		{"before nil", TimeBeforeMatcher{Matching: now}, nilTime, false},
+		{"before not a time", TimeBeforeMatcher{Matching: now}, "now", false},
+		{"between", TimeBetweenMatcher{From: now, To: now.Add(time.Hour)}, now.Add(time.Minute), true},
+		{"between bounds", TimeBetweenMatcher{From: now, To: now.Add(time.Hour)}, now.Add(time.Hour), true},
+		{"between earlier", TimeBetweenMatcher{From: now, To: now.Add(time.Hour)}, now.Add(-time.Minute), false},
+		{"between later", TimeBetweenMatcher{From: now, To: now.Add(time.Hour)}, now.Add(2 * time.Hour), false},
+		{"within", TimeWithinMatcher{Matching: now, Delta: 2 * time.Second}, now.Add(-time.Second), true},
+		{"within later", TimeWithinMatcher{Matching: now, Delta: 2 * time.Second}, now.Add(time.Second), true},
+		{"within mismatch", TimeWithinMatcher{Matching: now, Delta: 2 * time.Second}, now.Add(3 * time.Second), false},
+		{"equal", TimeEqualMatcher{Matching: now}, now.In(moscow), true},
+		{"equal mismatch", TimeEqualMatcher{Matching: now}, now.Add(time.Nanosecond), false},
+		{"equal same location", TimeEqualMatcher{Matching: now.UTC(), SameLocation: true}, now.In(moscow), false},
+		{
+			"equal truncated",
+			TimeEqualMatcher{Matching: time.Date(2019, 1, 1, 10, 0, 0, 999999999, time.UTC), Precision: time.Second},
+			time.Date(2019, 1, 1, 13, 0, 0, 0, moscow),
+			true,
+		},
+		{
+			"equal truncated mismatch",
+			TimeEqualMatcher{Matching: time.Date(2019, 1, 1, 10, 0, 0, 999999999, time.UTC), Precision: time.Second},
+			time.Date(2019, 1, 1, 10, 0, 1, 0, time.UTC),
+			false,
+		},
+		{
+			"equal rounded",
+			TimeEqualMatcher{Matching: time.Date(2019, 1, 1, 10, 0, 0, 999999999, time.UTC), Precision: time.Second, Round: true},
+			time.Date(2019, 1, 1, 10, 0, 1, 0, time.UTC),
+			true,
+		},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			if tst.matcher.Matches(tst.value) != tst.matches {
+				t.Errorf("expected %t for '%v' %v", tst.matches, tst.value, tst.matcher)
+			}
+		})
+	}
+}
+
+type testNullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+func TestTimeMatchersRepresentations(t *testing.T) {
+	now := time.Date(2019, 1, 1, 10, 0, 0, 0, time.UTC)
+	iso := types.ISOTime(now)
+	var nilISO *types.ISOTime
+
+	cases := []struct {
+		name  string
+		value interface{}
+		ok    bool
+	}{
+		{"time", now, true},
+		{"pointer to time", &now, true},
+		{"ISOTime", iso, true},
+		{"pointer to ISOTime", &iso, true},
+		{"nil ISOTime", nilISO, false},
+		{"RFC3339 string", "2019-01-01T10:00:00Z", true},
+		{"RFC3339 bytes", []byte("2019-01-01T13:00:00+03:00"), true},
+		{"RFC3339Nano string", "2019-01-01T10:00:00.000000000Z", true},
+		{"invalid string", "01.01.2019", false},
+		{"Unix timestamp", now.Unix(), true},
+		{"unsigned Unix timestamp", uint64(now.Unix()), true},
+		{"duration", time.Duration(now.Unix()), false},
+		{"sql.NullTime", sql.NullTime{Time: now, Valid: true}, true},
+		{"invalid sql.NullTime", sql.NullTime{Time: now}, false},
+		{"NullTime-like struct", &testNullTime{Time: now, Valid: true}, true},
+		{"nil", nil, false},
+		{"not a time", struct{}{}, false},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			matcher := TimeEqualMatcher{Matching: now}
+			if matcher.Matches(tst.value) != tst.ok {
+				t.Errorf("expected %t for '%v' as a value", tst.ok, tst.value)
+			}
+			matcher = TimeEqualMatcher{Matching: tst.value}
+			if matcher.Matches(now) != tst.ok {
+				t.Errorf("expected %t for '%v' as a matching", tst.ok, tst.value)
+			}
+			if (TimeMatcher{Matching: tst.value}).Matches(&iso) != tst.ok {
+				t.Errorf("expected %t for '%v' in TimeMatcher", tst.ok, tst.value)
+			}
+		})
+	}
+}
@@ -1,11 +1,17 @@
 package helpers
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"reflect"
 	"time"
 )
 
-// TimeMatcher a struct for convenient time fields checking
+// TimeMatcher a struct for convenient time fields checking.
+//
+// All time matchers accept time.Time values and other time representations on both sides (in Matching
+// and in checked values): types based on time.Time (e.g. types.ISOTime), pointers to them, RFC3339
+// strings, Unix timestamps in seconds and sql.NullTime-like structs (invalid ones never match)
 type TimeMatcher struct {
 	Matching interface{}
 }
@@ -15,12 +21,172 @@ func (tm TimeMatcher) String() string {
 	return fmt.Sprintf("match to time after %v", tm.Matching)
 }
 
-//Matches if input value is a time-based value and if it's more than in TimeMatcher then it will return true
+// Matches if input value is a time-based value and if it's more than in TimeMatcher then it will return true
 func (tm TimeMatcher) Matches(x interface{}) bool {
-	x, matching, success := toExample(x, tm.Matching, time.Time{})
+	t, ok := toTime(x)
+	if !ok {
+		return false
+	}
+	matching, ok := toTime(tm.Matching)
 	// the time should be more than Matcher's time
-	if !success || x.(time.Time).Before(matching.(time.Time)) {
+	return ok && !t.Before(matching)
+}
+
+// TimeBeforeMatcher checks that a time is not after Matching, e.g. a deadline
+type TimeBeforeMatcher struct {
+	Matching interface{}
+}
+
+// String return a string value of Matching data
+func (tm TimeBeforeMatcher) String() string {
+	return fmt.Sprintf("match to time before %v", tm.Matching)
+}
+
+// Matches return true if the input time is not after Matching
+func (tm TimeBeforeMatcher) Matches(x interface{}) bool {
+	t, ok := toTime(x)
+	if !ok {
+		return false
+	}
+	matching, ok := toTime(tm.Matching)
+	return ok && !t.After(matching)
+}
+
+// TimeBetweenMatcher checks that a time is in the range [From, To]
+type TimeBetweenMatcher struct {
+	From interface{}
+	To   interface{}
+}
+
+// String return a string value of the range
+func (tm TimeBetweenMatcher) String() string {
+	return fmt.Sprintf("match to time between %v and %v", tm.From, tm.To)
+}
+
+// Matches return true if the input time is not before From and not after To
+func (tm TimeBetweenMatcher) Matches(x interface{}) bool {
+	t, ok := toTime(x)
+	if !ok {
+		return false
+	}
+	from, ok := toTime(tm.From)
+	if !ok || t.Before(from) {
+		return false
+	}
+	to, ok := toTime(tm.To)
+	return ok && !t.After(to)
+}
+
+// TimeWithinMatcher checks that a time differs from Matching not more than by Delta,
+// e.g. "within 2 seconds of now"
+type TimeWithinMatcher struct {
+	Matching interface{}
+	Delta    time.Duration
+}
+
+// String return a string value of Matching data and the tolerance
+func (tm TimeWithinMatcher) String() string {
+	return fmt.Sprintf("match to time within %v of %v", tm.Delta, tm.Matching)
+}
+
+// Matches return true if the input time is in the range [Matching-Delta, Matching+Delta]
+func (tm TimeWithinMatcher) Matches(x interface{}) bool {
+	t, ok := toTime(x)
+	if !ok {
+		return false
+	}
+	matching, ok := toTime(tm.Matching)
+	if !ok {
+		return false
+	}
+	diff := t.Sub(matching)
+	return -tm.Delta <= diff && diff <= tm.Delta
+}
+
+// TimeEqualMatcher checks that a time is equal to Matching. Both times are truncated (or rounded
+// if Round is set) to Precision before comparison, e.g. to compare times after a DB round-trip
+// which loses nanoseconds. The comparison ignores timezones unless SameLocation is set
+type TimeEqualMatcher struct {
+	Matching     interface{}
+	Precision    time.Duration
+	Round        bool
+	SameLocation bool
+}
+
+// String return a string value of Matching data and the precision
+func (tm TimeEqualMatcher) String() string {
+	if tm.Precision <= 0 {
+		return fmt.Sprintf("match to time equal to %v", tm.Matching)
+	}
+	return fmt.Sprintf("match to time equal to %v with precision %v", tm.Matching, tm.Precision)
+}
+
+// Matches return true if the input time is equal to Matching with the precision
+func (tm TimeEqualMatcher) Matches(x interface{}) bool {
+	t, ok := toTime(x)
+	if !ok {
 		return false
 	}
-	return true
+	matching, ok := toTime(tm.Matching)
+	if !ok {
+		return false
+	}
+	if tm.SameLocation && t.Location().String() != matching.Location().String() {
+		return false
+	}
+	if tm.Round {
+		return t.Round(tm.Precision).Equal(matching.Round(tm.Precision))
+	}
+	return t.Truncate(tm.Precision).Equal(matching.Truncate(tm.Precision))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// toTime normalise a time representation to time.Time
+func toTime(x interface{}) (time.Time, bool) {
+	v := reflect.ValueOf(x)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() == reflect.Ptr {
+		return time.Time{}, false
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type().ConvertibleTo(timeType) {
+			return v.Convert(timeType).Interface().(time.Time), true
+		}
+		// sql.NullTime, pq.NullTime, etc.
+		valid := v.FieldByName("Valid")
+		t := v.FieldByName("Time")
+		if valid.IsValid() && valid.Kind() == reflect.Bool && t.IsValid() && t.Type() == timeType {
+			return t.Interface().(time.Time), valid.Bool()
+		}
+	case reflect.String:
+		t, err := time.Parse(time.RFC3339Nano, v.String())
+		return t, err == nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			t, err := time.Parse(time.RFC3339Nano, string(v.Bytes()))
+			return t, err == nil
+		}
+	// Unix timestamps are plain integers only, named ones (e.g. time.Duration) are not timestamps
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type().PkgPath() == "" {
+			return time.Unix(v.Int(), 0), true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Type().PkgPath() == "" {
+			return time.Unix(int64(v.Uint()), 0), true
+		}
+	}
+
+	// other driver.Valuer implementations which are not based on the types above
+	if valuer, ok := v.Interface().(driver.Valuer); ok {
+		if value, err := valuer.Value(); err == nil && value != nil && reflect.TypeOf(value) != v.Type() {
+			return toTime(value)
+		}
+	}
+	return time.Time{}, false
 }
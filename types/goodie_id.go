@@ -3,13 +3,18 @@ package types
 import (
 	"database/sql/driver"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"github.com/google/uuid"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
 )
 
+// BMGID is an identifier which is either a UUID or a legacy integer packed into
+// a UUID of the version 0xa (byte 6 is 0xa0, bytes 8-16 keep the integer)
 type BMGID struct {
 	uuid.UUID
 }
@@ -18,65 +23,277 @@ var typBMGID = reflect.TypeOf(BMGID{})
 
 var uuidVersion uuid.Version = 0xa
 
+// Construction
+
+// NewBMGID will generate random (version 4) BMGID
+func NewBMGID() BMGID {
+	return BMGID{UUID: uuid.New()}
+}
+
+// NewBMGIDv7 will generate time-ordered (version 7) BMGID: 48 bits of
+// Unix milliseconds followed by random bits. IDs are strictly monotonic
+// within the process, see BMGIDGenerator
+func NewBMGIDv7() BMGID {
+	return defaultBMGIDGenerator.New()
+}
+
+// BMGIDFromInt will pack legacy integer ID into BMGID
+func BMGIDFromInt(integer uint64) BMGID {
+	var id BMGID
+	id.UUID[6] = byte(uuidVersion) << 4
+	binary.LittleEndian.PutUint64(id.UUID[8:16], integer)
+	return id
+}
+
+// BMGIDFromString will parse UUID, decimal legacy integer or compact (base32,
+// base58, base62) presentation of BMGID, the encoding is detected by the length
+func BMGIDFromString(s string) (BMGID, error) {
+	if u, err := uuid.Parse(s); err == nil {
+		return BMGID{UUID: u}, nil
+	}
+	// longer digits are neither legacy IDs nor compact presentations
+	if len(s) <= 20 {
+		if integer, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return BMGIDFromInt(integer), nil
+		}
+	}
+	if id, ok := decodeCompact([]byte(s)); ok {
+		return id, nil
+	}
+	return BMGID{}, fmt.Errorf("invalid BMGID %q", s)
+}
+
+// IsLegacyInt will report whether BMGID keeps legacy integer ID, only
+// the exact layout made by BMGIDFromInt is accepted
+func (id BMGID) IsLegacyInt() bool {
+	for i := 0; i < 8; i++ {
+		if i == 6 {
+			continue
+		}
+		if id.UUID[i] != 0 {
+			return false
+		}
+	}
+	return id.UUID[6] == byte(uuidVersion)<<4
+}
+
+// Int will return legacy integer ID, the second value is false for other BMGIDs
+func (id BMGID) Int() (uint64, bool) {
+	if !id.IsLegacyInt() {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(id.UUID[8:16]), true
+}
+
 // JSON handling
 
 // MarshalJSON will marshal BMGID backward compatible way into JSON format
+// in BMGIDEncoding, legacy integer IDs are written as decimal strings
 func (id BMGID) MarshalJSON() ([]byte, error) {
-	if id.Version() == uuidVersion {
-		integer := binary.LittleEndian.Uint64(id.UUID[8:16])
-		return []byte("\"" + strconv.FormatUint(integer, 10) + "\""), nil
-	}
-	return []byte("\"" + id.String() + "\""), nil
+	b := make([]byte, 0, 38)
+	b = append(b, '"')
+	b = id.appendText(b)
+	return append(b, '"'), nil
 }
 
 // UnmarshalJSON will unmarshal JSON instance of BMG ID or
-// populate specific error if it cannot do so
+// populate specific error if it cannot do so. It accepts UUID and
+// decimal strings, JSON numbers of legacy integer IDs and null, which is
+// unmarshaled into zero BMGID
 func (id *BMGID) UnmarshalJSON(b []byte) error {
-	cur := strings.Trim(string(b), `"`)
-	if ui, uerr := uuid.Parse(cur); uerr == nil {
-		id.UUID = ui
+	if string(b) == "null" {
+		*id = BMGID{}
 		return nil
 	}
 
-	integer, ierr := strconv.Atoi(cur)
-	if ierr != nil {
-		return &json.UnmarshalTypeError{
-			Value:  cur,
-			Type:   typBMGID,
-			Struct: "BMGID",
-			Field:  "uuid",
+	cur := b
+	quoted := len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"'
+	if quoted {
+		cur = b[1 : len(b)-1]
+	}
+	if parsed, ok := parseJSONBMGID(cur, quoted); ok {
+		*id = parsed
+		return nil
+	}
+	return &json.UnmarshalTypeError{
+		Value:  string(b),
+		Type:   typBMGID,
+		Struct: "BMGID",
+		Field:  "uuid",
+	}
+}
+
+// parseJSONBMGID will parse unquoted content of JSON string or JSON number
+// without allocations, numbers may be legacy integer IDs only
+func parseJSONBMGID(b []byte, quoted bool) (BMGID, bool) {
+	if isDecimal(b) && len(b) <= 20 {
+		integer, ok := parseDecimal(b)
+		if !ok || !quoted && len(b) > 1 && b[0] == '0' {
+			return BMGID{}, false
+		}
+		return BMGIDFromInt(integer), true
+	}
+	if !quoted {
+		return BMGID{}, false
+	}
+	if id, ok := decodeCompact(b); ok {
+		return id, true
+	}
+	u, err := uuid.ParseBytes(b)
+	if err != nil {
+		return BMGID{}, false
+	}
+	return BMGID{UUID: u}, true
+}
+
+// parseDecimal will parse non-empty decimal digits into uint64
+func parseDecimal(b []byte) (uint64, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	var n uint64
+	for _, c := range b {
+		d := uint64(c - '0')
+		if n > (math.MaxUint64-d)/10 {
+			return 0, false
 		}
+		n = n*10 + d
 	}
+	return n, true
+}
 
-	bs := [16]byte{}
-	bs[6] = byte(uuidVersion) << 4
-	binary.LittleEndian.PutUint64(bs[8:16], uint64(integer))
-	id.UUID = bs
+const hexDigits = "0123456789abcdef"
 
-	return nil
+// appendUUID will append canonical text presentation of UUID
+func appendUUID(b []byte, u uuid.UUID) []byte {
+	for i, c := range u {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			b = append(b, '-')
+		}
+		b = append(b, hexDigits[c>>4], hexDigits[c&0x0f])
+	}
+	return b
 }
 
 // Text handling
 
-// MarshalText will marshal BMGID backward compatible way into text
+// MarshalText will marshal BMGID backward compatible way into text in
+// BMGIDEncoding, legacy integer IDs are written as decimal numbers
 func (id BMGID) MarshalText() ([]byte, error) {
-	return id.UUID.MarshalText()
+	return id.appendText(make([]byte, 0, 36)), nil
 }
 
-// UnmarshalText will unmarshal text presentation of BMG ID or
-// populate specific error if it cannot do so
+// UnmarshalText will unmarshal text presentation of BMG ID (UUID, decimal
+// legacy integer or compact encodings) or populate specific error if it cannot do so
 func (id *BMGID) UnmarshalText(b []byte) error {
-	return id.UUID.UnmarshalText(b)
+	parsed, err := BMGIDFromString(string(b))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// Binary handling
+
+// MarshalBinary will marshal BMGID into 16 bytes, it's used by gob as well
+func (id BMGID) MarshalBinary() ([]byte, error) {
+	return id.UUID.MarshalBinary()
+}
+
+// UnmarshalBinary will unmarshal 16 bytes of BMGID
+func (id *BMGID) UnmarshalBinary(b []byte) error {
+	return id.UUID.UnmarshalBinary(b)
 }
 
 // SQL handling
 
+// SQLFormat is a form which BMGID is written to SQL database in
+type SQLFormat int
+
+const (
+	// SQLUUID all IDs are written as UUID strings (uuid columns)
+	SQLUUID SQLFormat = iota
+	// SQLLegacyInt legacy integer IDs are written as int64 (bigint columns),
+	// other IDs are written as UUID strings
+	SQLLegacyInt
+	// SQLText IDs are written as their canonical text presentation (text columns),
+	// legacy integer IDs are written as decimal numbers
+	SQLText
+)
+
+// BMGIDSQLFormat is a form which Value writes BMGID in, Scan understands all of them
+var BMGIDSQLFormat = SQLUUID
+
 // Value will create SQL insertable value from BMGID
 func (id BMGID) Value() (driver.Value, error) {
+	switch BMGIDSQLFormat {
+	case SQLLegacyInt:
+		if integer, ok := id.Int(); ok {
+			if integer > math.MaxInt64 {
+				return nil, fmt.Errorf("BMGID %d overflows bigint", integer)
+			}
+			return int64(integer), nil
+		}
+	case SQLText:
+		return id.Encode(EncodingCanonical), nil
+	}
 	return id.UUID.Value()
 }
 
-// Scan unmarshal database value to BMGID
+// Scan unmarshal database value to BMGID, it accepts UUID and decimal strings,
+// integers, 16 raw bytes and their PostgreSQL bytea hex presentation, NULL and
+// empty strings are scanned into zero BMGID
 func (id *BMGID) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*id = BMGID{}
+		return nil
+	case int64:
+		if v < 0 {
+			return fmt.Errorf("Scan: negative BMGID %d", v)
+		}
+		*id = BMGIDFromInt(uint64(v))
+		return nil
+	case []byte:
+		if len(v) == 16 && !isDecimal(v) {
+			return id.UUID.Scan(v)
+		}
+		return id.Scan(string(v))
+	case string:
+		if v == "" {
+			*id = BMGID{}
+			return nil
+		}
+		if strings.HasPrefix(v, `\x`) {
+			return id.scanHex(v[2:])
+		}
+		parsed, err := BMGIDFromString(v)
+		if err != nil {
+			return fmt.Errorf("Scan: %v", err)
+		}
+		*id = parsed
+		return nil
+	}
 	return id.UUID.Scan(value)
 }
+
+// scanHex will unmarshal 16 bytes of BMGID in hex format of PostgreSQL bytea
+func (id *BMGID) scanHex(s string) error {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 16 {
+		return fmt.Errorf("Scan: invalid bytea BMGID %q", s)
+	}
+	copy(id.UUID[:], b)
+	return nil
+}
+
+// isDecimal will report whether bytes are decimal digits only
+func isDecimal(b []byte) bool {
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,229 @@
+package types
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+)
+
+// Alphabets of compact encodings, they are composed the same way as the alphabets
+// of the helpers package, which is not imported to keep the package dependencies small
+const (
+	alphabetDigits    = "0123456789"
+	alphabetUppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	alphabetLowercase = "abcdefghijklmnopqrstuvwxyz"
+
+	// base62Alphabet digits, uppercase and lowercase letters
+	base62Alphabet = alphabetDigits + alphabetUppercase + alphabetLowercase
+	// base58Alphabet is the Bitcoin alphabet without 0, O, I and l
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	// base32Alphabet is the Crockford alphabet without I, L, O and U
+	base32Alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// Encoding is a string presentation of BMGID
+type Encoding int
+
+const (
+	// EncodingCanonical UUID text, legacy integer IDs are decimal numbers
+	EncodingCanonical Encoding = iota
+	// EncodingBase32 Crockford base32, 26 characters
+	EncodingBase32
+	// EncodingBase58 base58 with the Bitcoin alphabet, 23 characters, the first one is always "1",
+	// so base58 and base62 presentations differ in the length
+	EncodingBase58
+	// EncodingBase62 base62 of digits, uppercase and lowercase letters, 22 characters
+	EncodingBase62
+)
+
+// BMGIDEncoding is an encoding which MarshalJSON and MarshalText write BMGID in, legacy
+// integer IDs are always written as decimal numbers, as well as IDs which presentation
+// consists of digits only (e.g. the zero ID) are written canonically. Parsing detects
+// encodings by the length and doesn't depend on BMGIDEncoding
+var BMGIDEncoding = EncodingCanonical
+
+// compactEncoding describes a fixed-width encoding of 128 bits
+type compactEncoding struct {
+	alphabet string
+	width    int
+	decode   [256]byte // a digit + 1 for every accepted character, 0 for others
+}
+
+var (
+	base32Encoding = newCompactEncoding(base32Alphabet, 26)
+	base58Encoding = newCompactEncoding(base58Alphabet, 23)
+	base62Encoding = newCompactEncoding(base62Alphabet, 22)
+)
+
+func newCompactEncoding(alphabet string, width int) *compactEncoding {
+	e := &compactEncoding{alphabet: alphabet, width: width}
+	for i := 0; i < len(alphabet); i++ {
+		e.decode[alphabet[i]] = byte(i + 1)
+	}
+	return e
+}
+
+// compact will return fixed-width encoding or nil for the canonical one
+func (e Encoding) compact() *compactEncoding {
+	switch e {
+	case EncodingBase32:
+		return base32Encoding
+	case EncodingBase58:
+		return base58Encoding
+	case EncodingBase62:
+		return base62Encoding
+	}
+	return nil
+}
+
+// String will return a name of the encoding
+func (e Encoding) String() string {
+	switch e {
+	case EncodingCanonical:
+		return "canonical"
+	case EncodingBase32:
+		return "base32"
+	case EncodingBase58:
+		return "base58"
+	case EncodingBase62:
+		return "base62"
+	}
+	return "Encoding(" + strconv.Itoa(int(e)) + ")"
+}
+
+// Encode will return string presentation of BMGID in the encoding, all 128 bits
+// are encoded, so legacy integer IDs are encoded as well
+func (id BMGID) Encode(e Encoding) string {
+	return string(id.appendEncoded(make([]byte, 0, 36), e))
+}
+
+// DecodeBMGID will parse string presentation of BMGID in the encoding
+func DecodeBMGID(s string, e Encoding) (BMGID, error) {
+	if c := e.compact(); c != nil {
+		if id, ok := c.decodeBytes([]byte(s)); ok {
+			return id, nil
+		}
+		return BMGID{}, fmt.Errorf("invalid %s BMGID %q", e, s)
+	}
+	return BMGIDFromString(s)
+}
+
+// appendEncoded will append BMGID in the encoding
+func (id BMGID) appendEncoded(b []byte, e Encoding) []byte {
+	if c := e.compact(); c != nil {
+		return c.append(b, id)
+	}
+	if integer, ok := id.Int(); ok {
+		return strconv.AppendUint(b, integer, 10)
+	}
+	return appendUUID(b, id.UUID)
+}
+
+// appendText will append BMGID in BMGIDEncoding, legacy integer IDs as decimal numbers
+func (id BMGID) appendText(b []byte) []byte {
+	if id.IsLegacyInt() || BMGIDEncoding == EncodingCanonical {
+		return id.appendEncoded(b, EncodingCanonical)
+	}
+	start := len(b)
+	b = id.appendEncoded(b, BMGIDEncoding)
+	// digits only would be parsed as a legacy integer ID
+	if isDecimal(b[start:]) {
+		return id.appendEncoded(b[:start], EncodingCanonical)
+	}
+	return b
+}
+
+// append will append fixed-width presentation of BMGID
+func (e *compactEncoding) append(b []byte, id BMGID) []byte {
+	hi, lo := bigEndian(id)
+	base := uint64(len(e.alphabet))
+	start := len(b)
+	for i := 0; i < e.width; i++ {
+		b = append(b, 0)
+	}
+	for i := len(b) - 1; i >= start; i-- {
+		var r uint64
+		hi, r = bits.Div64(0, hi, base)
+		lo, r = bits.Div64(r, lo, base)
+		b[i] = e.alphabet[r]
+	}
+	return b
+}
+
+// decodeBytes will parse fixed-width presentation of BMGID without allocations
+func (e *compactEncoding) decodeBytes(s []byte) (BMGID, bool) {
+	if len(s) != e.width {
+		return BMGID{}, false
+	}
+	base := uint64(len(e.alphabet))
+	var hi, lo uint64
+	for _, c := range s {
+		digit := e.decode[e.normalize(c)]
+		if digit == 0 {
+			return BMGID{}, false
+		}
+		// (hi, lo) = (hi, lo) * base + digit - 1
+		overflow, hiLo := bits.Mul64(hi, base)
+		loHi, loLo := bits.Mul64(lo, base)
+		var carry uint64
+		lo, carry = bits.Add64(loLo, uint64(digit-1), 0)
+		hi, carry = bits.Add64(hiLo, loHi, carry)
+		if overflow != 0 || carry != 0 {
+			return BMGID{}, false
+		}
+	}
+	return fromBigEndian(hi, lo), true
+}
+
+// normalize will map aliases of Crockford base32 characters, other encodings are case-sensitive
+func (e *compactEncoding) normalize(c byte) byte {
+	if e != base32Encoding {
+		return c
+	}
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	switch c {
+	case 'O':
+		return '0'
+	case 'I', 'L':
+		return '1'
+	}
+	return c
+}
+
+// decodeCompact will detect the compact encoding by the length and parse BMGID,
+// digits only are not accepted, they may be a mistyped legacy integer ID
+func decodeCompact(s []byte) (BMGID, bool) {
+	if isDecimal(s) {
+		return BMGID{}, false
+	}
+	switch len(s) {
+	case base32Encoding.width:
+		return base32Encoding.decodeBytes(s)
+	case base58Encoding.width:
+		return base58Encoding.decodeBytes(s)
+	case base62Encoding.width:
+		return base62Encoding.decodeBytes(s)
+	}
+	return BMGID{}, false
+}
+
+// bigEndian will return BMGID as a 128-bit number
+func bigEndian(id BMGID) (hi, lo uint64) {
+	for i := 0; i < 8; i++ {
+		hi = hi<<8 | uint64(id.UUID[i])
+		lo = lo<<8 | uint64(id.UUID[i+8])
+	}
+	return hi, lo
+}
+
+// fromBigEndian will create BMGID from a 128-bit number
+func fromBigEndian(hi, lo uint64) BMGID {
+	var id BMGID
+	for i := 7; i >= 0; i-- {
+		id.UUID[i], id.UUID[i+8] = byte(hi), byte(lo)
+		hi, lo = hi>>8, lo>>8
+	}
+	return id
+}
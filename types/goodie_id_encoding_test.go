@@ -0,0 +1,203 @@
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEncode(t *testing.T) {
+	uuid4 := BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}
+	max := BMGID{UUID: uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff")}
+	cases := []struct {
+		name     string
+		id       BMGID
+		encoding Encoding
+		expected string
+	}{
+		{"canonical UUID", uuid4, EncodingCanonical, "a1c87dd5-265a-499f-abf6-ad79008afa14"},
+		{"canonical integer", BMGIDFromInt(12345), EncodingCanonical, "12345"},
+		{"base32", uuid4, EncodingBase32, "51S1YXA9JT96FTQXNDF408NYGM"},
+		{"base58", uuid4, EncodingBase58, "1LyhuEXf57oLXEQzsR2SzEf"},
+		{"base62", uuid4, EncodingBase62, "4vHO9ML54vG1PA0Zt2T73c"},
+		{"base32 zero", BMGID{}, EncodingBase32, "00000000000000000000000000"},
+		{"base58 zero", BMGID{}, EncodingBase58, "11111111111111111111111"},
+		{"base62 zero", BMGID{}, EncodingBase62, "0000000000000000000000"},
+		{"base32 max", max, EncodingBase32, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ"},
+		{"base58 max", max, EncodingBase58, "1YcVfxkQb6JRzqk5kF2tNLv"},
+		{"base62 max", max, EncodingBase62, "7n42DGM5Tflk9n8mt7Fhc7"},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			if s := tst.id.Encode(tst.encoding); s != tst.expected {
+				t.Errorf("expected '%s', got '%s'", tst.expected, s)
+			}
+			id, err := DecodeBMGID(tst.expected, tst.encoding)
+			if err != nil || id != tst.id {
+				t.Errorf("expected '%s', got '%s' (%v)", tst.id, id, err)
+			}
+		})
+	}
+}
+
+func TestDecodeBMGID(t *testing.T) {
+	uuid4 := BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}
+	cases := []struct {
+		name     string
+		text     string
+		encoding Encoding
+		fail     bool
+	}{
+		{"base32 lowercase", "51s1yxa9jt96ftqxndf408nygm", EncodingBase32, false},
+		{"base32 aliases", "51S1YXA9JT96FTQXNDF4o8NYGM", EncodingBase32, false},
+		{"base32 overflow", "8ZZZZZZZZZZZZZZZZZZZZZZZZZ", EncodingBase32, true},
+		{"base32 short", "51S1YXA9JT96FTQXNDF408NYG", EncodingBase32, true},
+		{"base32 invalid character", "51S1YXA9JT96FTQXNDF408NYGU", EncodingBase32, true},
+		{"base58 excluded character", "1LyhuEXf57oLXEQzsR2SzE0", EncodingBase58, true},
+		{"base58 overflow", "2111111111111111111111111", EncodingBase58, true},
+		{"base58 short", "LyhuEXf57oLXEQzsR2SzEf", EncodingBase58, true},
+		{"base62 overflow", "zzzzzzzzzzzzzzzzzzzzzz", EncodingBase62, true},
+		{"base62 invalid character", "4vHO9ML54vG1PA0Zt2T73-", EncodingBase62, true},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			id, err := DecodeBMGID(tst.text, tst.encoding)
+			if tst.fail {
+				if err == nil {
+					t.Errorf("expected error, got '%s'", id)
+				}
+				return
+			}
+			if err != nil || id != uuid4 {
+				t.Errorf("expected '%s', got '%s' (%v)", uuid4, id, err)
+			}
+		})
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	defer func(encoding Encoding) { BMGIDEncoding = encoding }(BMGIDEncoding)
+
+	uuid4 := BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}
+	for _, text := range []string{
+		"a1c87dd5-265a-499f-abf6-ad79008afa14",
+		"51S1YXA9JT96FTQXNDF408NYGM",
+		"1LyhuEXf57oLXEQzsR2SzEf",
+		"4vHO9ML54vG1PA0Zt2T73c",
+	} {
+		id, err := BMGIDFromString(text)
+		if err != nil || id != uuid4 {
+			t.Errorf("expected '%s' from '%s', got '%s' (%v)", uuid4, text, id, err)
+		}
+		id = BMGID{}
+		if err := id.UnmarshalText([]byte(text)); err != nil || id != uuid4 {
+			t.Errorf("expected '%s' from '%s', got '%s' (%v)", uuid4, text, id, err)
+		}
+		id = BMGID{}
+		if err := id.UnmarshalJSON([]byte(`"` + text + `"`)); err != nil || id != uuid4 {
+			t.Errorf("expected '%s' from '%s', got '%s' (%v)", uuid4, text, id, err)
+		}
+	}
+
+	// digits only are neither legacy IDs nor compact presentations
+	for _, text := range []string{"1234567890123456789012", "11111111111111111111111", "00000000000000000000000000"} {
+		if id, err := BMGIDFromString(text); err == nil {
+			t.Errorf("expected error for '%s', got '%s'", text, id)
+		}
+		if err := new(BMGID).UnmarshalJSON([]byte(`"` + text + `"`)); err == nil {
+			t.Errorf("expected JSON error for '%s'", text)
+		}
+	}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	defer func(encoding Encoding) { BMGIDEncoding = encoding }(BMGIDEncoding)
+
+	encodings := []Encoding{EncodingCanonical, EncodingBase32, EncodingBase58, EncodingBase62}
+	for _, encoding := range encodings {
+		t.Run(encoding.String(), func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				id := NewBMGID()
+				if i%2 == 1 {
+					id = NewBMGIDv7()
+				}
+				text := id.Encode(encoding)
+				// detection must not depend on the configured encoding
+				for _, configured := range encodings {
+					BMGIDEncoding = configured
+					if parsed, err := BMGIDFromString(text); err != nil || parsed != id {
+						t.Fatalf("expected '%s' from '%s', got '%s' (%v)", id, text, parsed, err)
+					}
+					var parsed BMGID
+					if err := parsed.UnmarshalText([]byte(text)); err != nil || parsed != id {
+						t.Fatalf("expected '%s' from text '%s', got '%s' (%v)", id, text, parsed, err)
+					}
+					parsed = BMGID{}
+					if err := parsed.UnmarshalJSON([]byte(`"` + text + `"`)); err != nil || parsed != id {
+						t.Fatalf("expected '%s' from JSON '%s', got '%s' (%v)", id, text, parsed, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalEncoding(t *testing.T) {
+	defer func(encoding Encoding) { BMGIDEncoding = encoding }(BMGIDEncoding)
+
+	uuid4 := BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}
+	cases := []struct {
+		encoding Encoding
+		id       BMGID
+		expected string
+	}{
+		{EncodingCanonical, uuid4, "a1c87dd5-265a-499f-abf6-ad79008afa14"},
+		{EncodingBase32, uuid4, "51S1YXA9JT96FTQXNDF408NYGM"},
+		{EncodingBase58, uuid4, "1LyhuEXf57oLXEQzsR2SzEf"},
+		{EncodingBase62, uuid4, "4vHO9ML54vG1PA0Zt2T73c"},
+		{EncodingBase62, BMGIDFromInt(math.MaxUint64), "18446744073709551615"},
+		{EncodingBase62, BMGID{}, "00000000-0000-0000-0000-000000000000"},
+		{EncodingBase58, BMGID{}, "00000000-0000-0000-0000-000000000000"},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.encoding.String(), func(t *testing.T) {
+			BMGIDEncoding = tst.encoding
+			if bs, _ := tst.id.MarshalText(); string(bs) != tst.expected {
+				t.Errorf("expected '%s', got '%s'", tst.expected, bs)
+			}
+			bs, _ := json.Marshal(tst.id)
+			if string(bs) != `"`+tst.expected+`"` {
+				t.Errorf("expected '\"%s\"', got '%s'", tst.expected, bs)
+			}
+			var id BMGID
+			if err := json.Unmarshal(bs, &id); err != nil || id != tst.id {
+				t.Errorf("expected '%s', got '%s' (%v)", tst.id, id, err)
+			}
+			if val, _ := tst.id.Value(); val != tst.id.String() {
+				t.Errorf("expected SQL value '%s', got '%v'", tst.id, val)
+			}
+		})
+	}
+}
+
+func BenchmarkBase62Encode(b *testing.B) {
+	b.ReportAllocs()
+	id := NewBMGID()
+	for i := 0; i < b.N; i++ {
+		id.Encode(EncodingBase62)
+	}
+}
+
+func BenchmarkBase62UnmarshalJSON(b *testing.B) {
+	b.ReportAllocs()
+	bs := []byte(`"4vHO9ML54vG1PA0Zt2T73c"`)
+	id := BMGID{}
+	for i := 0; i < b.N; i++ {
+		id.UnmarshalJSON(bs)
+	}
+}
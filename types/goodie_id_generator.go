@@ -0,0 +1,138 @@
+package types
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// randomBitsMask covers 62 random bits of the lower half of UUID v7
+const randomBitsMask = 1<<62 - 1
+
+var defaultBMGIDGenerator = NewBMGIDGenerator()
+
+// BMGIDGenerator will generate time-ordered (version 7) BMGIDs which are strictly
+// monotonic even within the same millisecond and across goroutines: if the clock
+// has not moved forward, random bits of the previous ID are incremented. It's
+// safe for concurrent use
+type BMGIDGenerator struct {
+	Now    func() time.Time // clock, time.Now if it's nil
+	Random io.Reader        // source of random bits, crypto/rand if it's nil
+
+	mu   sync.Mutex
+	last BMGID
+}
+
+// NewBMGIDGenerator will create generator with the system clock and crypto/rand
+func NewBMGIDGenerator() *BMGIDGenerator {
+	return &BMGIDGenerator{Now: time.Now, Random: rand.Reader}
+}
+
+// New will generate next BMGID, it panics if random bits cannot be read
+func (g *BMGIDGenerator) New() BMGID {
+	id, err := g.Next()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// Next will generate next BMGID which is greater than all previous ones
+func (g *BMGIDGenerator) Next() (BMGID, error) {
+	now, random := g.Now, g.Random
+	if now == nil {
+		now = time.Now
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(now().UnixNano() / int64(time.Millisecond))
+	if last := g.last.milliseconds(); ms <= last && g.last != (BMGID{}) {
+		if next, ok := g.last.increment(); ok {
+			g.last = next
+			return next, nil
+		}
+		ms = last + 1
+	}
+	id, err := newBMGIDv7(ms, random)
+	if err != nil {
+		return BMGID{}, err
+	}
+	g.last = id
+	return id, nil
+}
+
+// newBMGIDv7 will create UUID v7 with the timestamp and random bits
+func newBMGIDv7(ms uint64, random io.Reader) (BMGID, error) {
+	var id BMGID
+	if _, err := io.ReadFull(random, id.UUID[6:]); err != nil {
+		return BMGID{}, err
+	}
+	binary.BigEndian.PutUint16(id.UUID[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id.UUID[2:6], uint32(ms))
+	id.UUID[6] = id.UUID[6]&0x0f | 0x70 // version 7
+	id.UUID[8] = id.UUID[8]&0x3f | 0x80 // RFC 4122 variant
+	return id, nil
+}
+
+// milliseconds will return Unix milliseconds of UUID v7
+func (id BMGID) milliseconds() uint64 {
+	return uint64(binary.BigEndian.Uint16(id.UUID[0:2]))<<32 | uint64(binary.BigEndian.Uint32(id.UUID[2:6]))
+}
+
+// increment will add one to 74 random bits of UUID v7, false is returned on overflow
+func (id BMGID) increment() (BMGID, bool) {
+	high := binary.BigEndian.Uint16(id.UUID[6:8]) & 0x0fff
+	low := binary.BigEndian.Uint64(id.UUID[8:16])&randomBitsMask + 1
+	if low > randomBitsMask {
+		low = 0
+		high++
+		if high > 0x0fff {
+			return id, false
+		}
+	}
+	binary.BigEndian.PutUint16(id.UUID[6:8], 0x7000|high)
+	binary.BigEndian.PutUint64(id.UUID[8:16], 0x8000000000000000|low)
+	return id, true
+}
+
+// Timestamp will return creation time of time-ordered (version 7) BMGID,
+// the second value is false for other BMGIDs
+func (id BMGID) Timestamp() (ISOTime, bool) {
+	if id.Version() != 7 || id.Variant() != uuid.RFC4122 {
+		return ISOTime{}, false
+	}
+	return ISOTime(time.UnixMilli(int64(id.milliseconds())).UTC()), true
+}
+
+// Compare will return -1, 0 or 1 if BMGID is less than, equal to or greater than
+// the other one. Time-ordered BMGIDs are ordered by creation time, legacy integer
+// IDs are ordered by their integers and precede other IDs
+func (id BMGID) Compare(other BMGID) int {
+	a, aLegacy := id.Int()
+	b, bLegacy := other.Int()
+	switch {
+	case aLegacy && bLegacy:
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+		return 0
+	case aLegacy:
+		return -1
+	case bLegacy:
+		return 1
+	}
+	return bytes.Compare(id.UUID[:], other.UUID[:])
+}
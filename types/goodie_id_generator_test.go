@@ -0,0 +1,138 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// failingReader is a source of random bits which always fails
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("no random bits")
+}
+
+func TestBMGIDGeneratorMonotonic(t *testing.T) {
+	now := time.Date(2020, 3, 4, 5, 6, 7, 8e6, time.UTC)
+	clock := now
+	g := &BMGIDGenerator{Now: func() time.Time { return clock }}
+
+	prev := g.New()
+	for i := 0; i < 1000; i++ {
+		if i == 500 {
+			clock = now.Add(-time.Second) // the clock goes backwards
+		}
+		id := g.New()
+		if id.Compare(prev) <= 0 || id.String() <= prev.String() {
+			t.Fatalf("expected '%s' to be after '%s'", id, prev)
+		}
+		if ts, ok := id.Timestamp(); !ok || !time.Time(ts).Equal(now) {
+			t.Fatalf("expected timestamp %s, got %s", now, time.Time(ts))
+		}
+		prev = id
+	}
+}
+
+func TestBMGIDGeneratorOverflow(t *testing.T) {
+	now := time.Date(2020, 3, 4, 5, 6, 7, 8e6, time.UTC)
+	ones := bytes.Repeat([]byte{0xff}, 10)
+	g := &BMGIDGenerator{
+		Now:    func() time.Time { return now },
+		Random: bytes.NewReader(append(append([]byte{}, ones...), ones...)),
+	}
+
+	first := g.New()
+	if first.String() != "0170a3ef-ce20-7fff-bfff-ffffffffffff" {
+		t.Fatalf("expected max random bits, got '%s'", first)
+	}
+	next := g.New()
+	if ts, _ := next.Timestamp(); !time.Time(ts).Equal(now.Add(time.Millisecond)) {
+		t.Errorf("expected the next millisecond on overflow, got %s", time.Time(ts))
+	}
+	if next.Compare(first) <= 0 {
+		t.Errorf("expected '%s' to be after '%s'", next, first)
+	}
+
+	g = &BMGIDGenerator{Random: failingReader{}}
+	if _, err := g.Next(); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestBMGIDGeneratorConcurrent(t *testing.T) {
+	g := NewBMGIDGenerator()
+	ids := make([]BMGID, 0, 8000)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			generated := make([]BMGID, 0, 1000)
+			for j := 0; j < 1000; j++ {
+				id := g.New()
+				if len(generated) > 0 && id.Compare(generated[len(generated)-1]) <= 0 {
+					t.Errorf("expected '%s' to be after '%s'", id, generated[len(generated)-1])
+				}
+				generated = append(generated, id)
+			}
+			mu.Lock()
+			ids = append(ids, generated...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Compare(ids[j]) < 0 })
+	for i := 1; i < len(ids); i++ {
+		if ids[i] == ids[i-1] {
+			t.Fatalf("duplicated ID '%s'", ids[i])
+		}
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	if _, ok := NewBMGID().Timestamp(); ok {
+		t.Errorf("expected no timestamp of UUID v4")
+	}
+	if _, ok := BMGIDFromInt(12345).Timestamp(); ok {
+		t.Errorf("expected no timestamp of legacy ID")
+	}
+	before := time.Now().Truncate(time.Millisecond)
+	ts, ok := NewBMGIDv7().Timestamp()
+	if !ok || time.Time(ts).Before(before) || time.Time(ts).After(time.Now()) {
+		t.Errorf("expected timestamp after %s, got %s", before, time.Time(ts))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	low := BMGID{UUID: uuid.MustParse("0170a3ef-ce20-7000-8000-000000000000")}
+	high := BMGID{UUID: uuid.MustParse("0170a3ef-ce21-7000-8000-000000000000")}
+	cases := []struct {
+		name     string
+		a, b     BMGID
+		expected int
+	}{
+		{"equal", low, low, 0},
+		{"less", low, high, -1},
+		{"greater", high, low, 1},
+		{"legacy integers", BMGIDFromInt(256), BMGIDFromInt(1), 1},
+		{"equal legacy integers", BMGIDFromInt(1), BMGIDFromInt(1), 0},
+		{"legacy and UUID", BMGIDFromInt(1 << 60), low, -1},
+		{"UUID and legacy", low, BMGIDFromInt(1), 1},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			if c := tst.a.Compare(tst.b); c != tst.expected {
+				t.Errorf("expected %d, got %d", tst.expected, c)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// NewBMGIDv5 will derive name-based (version 5, SHA-1) BMGID from the namespace and key parts,
+// the same namespace and parts always give the same ID, so reruns of imports don't duplicate
+// records. The number of parts and every part length are hashed too, so ("ab", "c"), ("a", "bc")
+// and ("abc") give different IDs. Use NewBMGIDv5Name for the standard RFC 4122 ID of a name
+func NewBMGIDv5(namespace BMGID, parts ...string) BMGID {
+	return BMGID{UUID: uuid.NewSHA1(namespace.UUID, nameKey(parts))}
+}
+
+// NewBMGIDv3 will derive name-based (version 3, MD5) BMGID from the namespace and key parts
+// like NewBMGIDv5. Version 3 is kept for existing MD5-based IDs, prefer NewBMGIDv5 for new ones
+func NewBMGIDv3(namespace BMGID, parts ...string) BMGID {
+	return BMGID{UUID: uuid.NewMD5(namespace.UUID, nameKey(parts))}
+}
+
+// NewBMGIDv5Name will derive the standard RFC 4122 version 5 BMGID of the name, e.g.
+// uuid_generate_v5() of PostgreSQL gives the same ID
+func NewBMGIDv5Name(namespace BMGID, name string) BMGID {
+	return BMGID{UUID: uuid.NewSHA1(namespace.UUID, []byte(name))}
+}
+
+// NewBMGIDv3Name will derive the standard RFC 4122 version 3 BMGID of the name
+func NewBMGIDv3Name(namespace BMGID, name string) BMGID {
+	return BMGID{UUID: uuid.NewMD5(namespace.UUID, []byte(name))}
+}
+
+// nameKey will join the uvarint number of parts and parts, every one is prefixed
+// by its uvarint length
+func nameKey(parts []string) []byte {
+	size := binary.MaxVarintLen64
+	for _, part := range parts {
+		size += binary.MaxVarintLen64 + len(part)
+	}
+	key := make([]byte, size)
+	n := binary.PutUvarint(key, uint64(len(parts)))
+	for _, part := range parts {
+		n += binary.PutUvarint(key[n:], uint64(len(part)))
+		n += copy(key[n:], part)
+	}
+	return key[:n]
+}
+
+// Namespace registry
+
+var namespaces = struct {
+	sync.RWMutex
+	ids map[string]BMGID
+}{ids: map[string]BMGID{
+	"dns":  {UUID: uuid.NameSpaceDNS},
+	"url":  {UUID: uuid.NameSpaceURL},
+	"oid":  {UUID: uuid.NameSpaceOID},
+	"x500": {UUID: uuid.NameSpaceX500},
+}}
+
+// RegisterNamespace will make the namespace available by the name, so importers of different
+// teams derive IDs consistently. Standard namespaces are registered as "dns", "url", "oid" and
+// "x500". It panics if the name is empty, the namespace is zero or the name is already registered
+// with another namespace, registering the same pair twice is allowed
+func RegisterNamespace(name string, namespace BMGID) {
+	if name == "" {
+		panic("RegisterNamespace: empty name")
+	}
+	if namespace == (BMGID{}) {
+		panic(fmt.Sprintf("RegisterNamespace: zero namespace %q", name))
+	}
+	namespaces.Lock()
+	defer namespaces.Unlock()
+	if registered, ok := namespaces.ids[name]; ok && registered != namespace {
+		panic(fmt.Sprintf("RegisterNamespace: namespace %q is already registered as %s", name, registered))
+	}
+	namespaces.ids[name] = namespace
+}
+
+// Namespace return the namespace registered by the name
+func Namespace(name string) (BMGID, bool) {
+	namespaces.RLock()
+	defer namespaces.RUnlock()
+	namespace, ok := namespaces.ids[name]
+	return namespace, ok
+}
+
+// NewNamedBMGID will derive version 5 BMGID from key parts in the namespace registered by the name
+func NewNamedBMGID(namespace string, parts ...string) (BMGID, error) {
+	id, ok := Namespace(namespace)
+	if !ok {
+		return BMGID{}, fmt.Errorf("unknown BMGID namespace %q", namespace)
+	}
+	return NewBMGIDv5(id, parts...), nil
+}
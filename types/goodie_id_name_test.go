@@ -0,0 +1,103 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNewBMGIDv5(t *testing.T) {
+	dns, _ := Namespace("dns")
+	url, _ := Namespace("url")
+	cases := []struct {
+		name     string
+		id       BMGID
+		expected string
+	}{
+		{"v5", NewBMGIDv5(dns, "crm", "12345"), "d4a39c7d-bfbe-52a9-bcbc-0dc592405d64"},
+		{"v3", NewBMGIDv3(dns, "crm", "12345"), "2e45fd7c-9895-39f4-9bad-431a43f9fc58"},
+		{"RFC 4122 v5", NewBMGIDv5Name(dns, "www.example.com"), "2ed6657d-e927-568b-95e1-2665a8aea6a2"},
+		{"RFC 4122 v3", NewBMGIDv3Name(dns, "www.example.com"), "5df41881-3aed-3515-88a7-2f4a814cf09e"},
+		{"no parts", NewBMGIDv5(dns), "d73aaa6c-907e-57b0-8739-29487068eee4"},
+		{"url", NewBMGIDv5(url, "order", "42"), "ad9ded4a-6df4-5b13-9a52-788816bdf8c3"},
+	}
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			if tst.id.String() != tst.expected {
+				t.Errorf("expected %s, got %s", tst.expected, tst.id)
+			}
+			if tst.id.IsLegacyInt() {
+				t.Errorf("expected not legacy ID")
+			}
+		})
+	}
+
+	different := []struct {
+		name string
+		a, b []string
+	}{
+		{"shifted parts", []string{"ab", "c"}, []string{"a", "bc"}},
+		{"joined parts", []string{"ab"}, []string{"a", "b"}},
+		{"encoded parts", []string{"\x01a\x01b"}, []string{"a", "b"}},
+		{"encoded count", []string{"\x02\x01a\x01b"}, []string{"a", "b"}},
+		{"empty part", nil, []string{""}},
+		{"empty parts", []string{""}, []string{"", ""}},
+	}
+	for _, tst := range different {
+		t.Run(tst.name, func(t *testing.T) {
+			if NewBMGIDv5(dns, tst.a...) == NewBMGIDv5(dns, tst.b...) {
+				t.Errorf("expected different IDs of %q and %q", tst.a, tst.b)
+			}
+		})
+	}
+	if NewBMGIDv5Name(dns, "a") != (BMGID{UUID: uuid.NewSHA1(uuid.NameSpaceDNS, []byte("a"))}) {
+		t.Errorf("expected the standard ID of a name")
+	}
+	if NewBMGIDv5Name(dns, "a") == NewBMGIDv5(dns, "a") {
+		t.Errorf("expected different IDs of a name and a single part")
+	}
+	if NewBMGIDv5(dns, "a") == NewBMGIDv5(url, "a") {
+		t.Errorf("expected different IDs of different namespaces")
+	}
+}
+
+func TestNamespaceRegistry(t *testing.T) {
+	crm := NewBMGIDv5(BMGID{}, "crm")
+	RegisterNamespace("test-crm", crm)
+	RegisterNamespace("test-crm", crm)
+
+	if got, ok := Namespace("test-crm"); !ok || got != crm {
+		t.Errorf("expected %s, got %s (%v)", crm, got, ok)
+	}
+	if _, ok := Namespace("test-unknown"); ok {
+		t.Errorf("expected unknown namespace")
+	}
+
+	id, err := NewNamedBMGID("test-crm", "contact", "7")
+	if err != nil || id != NewBMGIDv5(crm, "contact", "7") {
+		t.Errorf("unexpected ID %s (%v)", id, err)
+	}
+	if _, err := NewNamedBMGID("test-unknown", "7"); err == nil {
+		t.Errorf("expected error of unknown namespace")
+	}
+
+	panics := []struct {
+		name      string
+		namespace string
+		id        BMGID
+	}{
+		{"empty name", "", crm},
+		{"zero namespace", "test-zero", BMGID{}},
+		{"conflict", "test-crm", NewBMGID()},
+	}
+	for _, tst := range panics {
+		t.Run(tst.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic")
+				}
+			}()
+			RegisterNamespace(tst.namespace, tst.id)
+		})
+	}
+}
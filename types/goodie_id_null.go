@@ -0,0 +1,177 @@
+package types
+
+import (
+	"database/sql/driver"
+
+	"github.com/go-pg/pg/types"
+	"github.com/jackc/pgtype"
+)
+
+// NullBMGID is a BMGID which may be null, e.g. a nullable foreign key. It's null
+// in JSON, NULL in SQL and empty in text if it's not Valid
+type NullBMGID struct {
+	BMGID
+	Valid bool // Valid is true if BMGID is not null
+}
+
+// NullBMGIDFrom will create valid NullBMGID
+func NullBMGIDFrom(id BMGID) NullBMGID {
+	return NullBMGID{BMGID: id, Valid: true}
+}
+
+// NullBMGIDFromPtr will create NullBMGID which is null for nil pointer
+func NullBMGIDFromPtr(id *BMGID) NullBMGID {
+	if id == nil {
+		return NullBMGID{}
+	}
+	return NullBMGIDFrom(*id)
+}
+
+// Ptr will return pointer to BMGID or nil if it's null
+func (n NullBMGID) Ptr() *BMGID {
+	if !n.Valid {
+		return nil
+	}
+	id := n.BMGID
+	return &id
+}
+
+// String will return string presentation of BMGID or empty string if it's null
+func (n NullBMGID) String() string {
+	if !n.Valid {
+		return ""
+	}
+	return n.BMGID.String()
+}
+
+// JSON handling
+
+// MarshalJSON will marshal NullBMGID into JSON format, null if it's not valid
+func (n NullBMGID) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.BMGID.MarshalJSON()
+}
+
+// UnmarshalJSON will unmarshal JSON instance of BMG ID or null
+func (n *NullBMGID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = NullBMGID{}
+		return nil
+	}
+	return n.set(n.BMGID.UnmarshalJSON(b))
+}
+
+// Text handling
+
+// MarshalText will marshal NullBMGID into text, empty if it's not valid
+func (n NullBMGID) MarshalText() ([]byte, error) {
+	if !n.Valid {
+		return []byte{}, nil
+	}
+	return n.BMGID.MarshalText()
+}
+
+// UnmarshalText will unmarshal text presentation of BMG ID, empty text is null
+func (n *NullBMGID) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*n = NullBMGID{}
+		return nil
+	}
+	return n.set(n.BMGID.UnmarshalText(b))
+}
+
+// Binary handling
+
+// MarshalBinary will marshal NullBMGID into 16 bytes, no bytes if it's not valid
+func (n NullBMGID) MarshalBinary() ([]byte, error) {
+	if !n.Valid {
+		return []byte{}, nil
+	}
+	return n.BMGID.MarshalBinary()
+}
+
+// UnmarshalBinary will unmarshal 16 bytes of BMGID, no bytes are null
+func (n *NullBMGID) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 {
+		*n = NullBMGID{}
+		return nil
+	}
+	return n.set(n.BMGID.UnmarshalBinary(b))
+}
+
+// SQL handling
+
+// Value will create SQL insertable value from NullBMGID, NULL if it's not valid
+func (n NullBMGID) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.BMGID.Value()
+}
+
+// Scan unmarshal database value to NullBMGID, see BMGID.Scan, NULL and
+// empty values are null
+func (n *NullBMGID) Scan(value interface{}) error {
+	if b, ok := value.([]byte); ok && len(b) == 0 || value == nil || value == "" {
+		*n = NullBMGID{}
+		return nil
+	}
+	return n.set(n.BMGID.Scan(value))
+}
+
+// go-pg handling
+
+var _ types.ValueAppender = NullBMGID{}
+
+// AppendValue will append NullBMGID to go-pg query, NULL if it's not valid
+func (n NullBMGID) AppendValue(b []byte, quote int) []byte {
+	if !n.Valid {
+		return types.AppendNull(b, quote)
+	}
+	return n.BMGID.AppendValue(b, quote)
+}
+
+// pgx handling
+
+var (
+	_ pgtype.TextEncoder   = NullBMGID{}
+	_ pgtype.TextDecoder   = &NullBMGID{}
+	_ pgtype.BinaryDecoder = &NullBMGID{}
+)
+
+// EncodeText will encode NullBMGID for pgx, nothing is appended if it's not valid
+func (n NullBMGID) EncodeText(ci *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.BMGID.EncodeText(ci, buf)
+}
+
+// DecodeText will decode NullBMGID from pgx text format
+func (n *NullBMGID) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+	if src == nil {
+		*n = NullBMGID{}
+		return nil
+	}
+	return n.set(n.BMGID.DecodeText(ci, src))
+}
+
+// DecodeBinary will decode NullBMGID from pgx binary format
+func (n *NullBMGID) DecodeBinary(ci *pgtype.ConnInfo, src []byte) error {
+	if src == nil {
+		*n = NullBMGID{}
+		return nil
+	}
+	return n.set(n.BMGID.DecodeBinary(ci, src))
+}
+
+// set will mark NullBMGID valid if BMGID has been decoded without errors
+func (n *NullBMGID) set(err error) error {
+	n.Valid = err == nil
+	if err != nil {
+		n.BMGID = BMGID{}
+	}
+	return err
+}
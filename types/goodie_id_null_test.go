@@ -0,0 +1,137 @@
+package types
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+)
+
+func TestNullBMGIDJSON(t *testing.T) {
+	type entity struct {
+		OwnerID  NullBMGID `json:"owner_id"`
+		ParentID BMGID     `json:"parent_id"`
+	}
+	cases := []struct {
+		name     string
+		json     string
+		expected entity
+		output   string
+	}{
+		{"null", `{"owner_id":null,"parent_id":null}`, entity{}, `{"owner_id":null,"parent_id":"00000000-0000-0000-0000-000000000000"}`},
+		{"missing", `{}`, entity{}, `{"owner_id":null,"parent_id":"00000000-0000-0000-0000-000000000000"}`},
+		{"integer", `{"owner_id":"12345","parent_id":"12345"}`,
+			entity{OwnerID: NullBMGIDFrom(BMGIDFromInt(12345)), ParentID: BMGIDFromInt(12345)},
+			`{"owner_id":"12345","parent_id":"12345"}`},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			var e entity
+			if err := json.Unmarshal([]byte(tst.json), &e); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if e != tst.expected {
+				t.Errorf("expected %v, got %v", tst.expected, e)
+			}
+			bs, _ := json.Marshal(e)
+			if string(bs) != tst.output {
+				t.Errorf("expected %s, got %s", tst.output, bs)
+			}
+		})
+	}
+
+	n := NullBMGIDFrom(NewBMGID())
+	if err := n.UnmarshalJSON([]byte(`"abc"`)); err == nil || n.Valid {
+		t.Errorf("expected error and null, got %v (%v)", n, err)
+	}
+}
+
+func TestNullBMGIDText(t *testing.T) {
+	n := NullBMGIDFrom(BMGIDFromInt(12345))
+	if bs, _ := n.MarshalText(); string(bs) != "12345" {
+		t.Errorf("expected '12345', got '%s'", bs)
+	}
+	if err := n.UnmarshalText([]byte{}); err != nil || n.Valid || n.String() != "" {
+		t.Errorf("expected null, got %v (%v)", n, err)
+	}
+	if bs, _ := n.MarshalText(); len(bs) != 0 {
+		t.Errorf("expected empty text, got '%s'", bs)
+	}
+	if err := n.UnmarshalText([]byte("12345")); err != nil || n != NullBMGIDFrom(BMGIDFromInt(12345)) {
+		t.Errorf("expected 12345, got %v (%v)", n, err)
+	}
+}
+
+func TestNullBMGIDGob(t *testing.T) {
+	for _, ref := range []NullBMGID{{}, NullBMGIDFrom(NewBMGID())} {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(ref); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tst := NullBMGIDFrom(NewBMGID())
+		if err := gob.NewDecoder(&buf).Decode(&tst); err != nil || tst != ref {
+			t.Errorf("expected %v, got %v (%v)", ref, tst, err)
+		}
+	}
+}
+
+func TestNullBMGIDSQL(t *testing.T) {
+	if val, err := (NullBMGID{}).Value(); val != nil || err != nil {
+		t.Errorf("expected NULL, got %v (%v)", val, err)
+	}
+	if val, _ := NullBMGIDFrom(BMGIDFromInt(12345)).Value(); val != "00000000-0000-a000-3930-000000000000" {
+		t.Errorf("expected UUID, got %v", val)
+	}
+	if b := (NullBMGID{}).AppendValue(nil, 1); string(b) != "NULL" {
+		t.Errorf("expected NULL, got %s", b)
+	}
+	if b, err := (NullBMGID{}).EncodeText(nil, nil); b != nil || err != nil {
+		t.Errorf("expected NULL, got %s (%v)", b, err)
+	}
+
+	cases := []struct {
+		name  string
+		value interface{}
+		valid bool
+	}{
+		{"NULL", nil, false},
+		{"empty string", "", false},
+		{"empty bytes", []byte{}, false},
+		{"bigint", int64(12345), true},
+		{"decimal string", "12345", true},
+	}
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			n := NullBMGIDFrom(NewBMGID())
+			if err := n.Scan(tst.value); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n.Valid != tst.valid || tst.valid && n.BMGID != BMGIDFromInt(12345) {
+				t.Errorf("expected valid %v, got %v", tst.valid, n)
+			}
+		})
+	}
+
+	n := NullBMGIDFrom(NewBMGID())
+	if err := n.DecodeText(nil, nil); err != nil || n.Valid {
+		t.Errorf("expected null, got %v (%v)", n, err)
+	}
+	if err := n.DecodeBinary(nil, []byte("12345")); err != nil || !n.Valid {
+		t.Errorf("expected valid, got %v (%v)", n, err)
+	}
+	if err := n.Scan(1.5); err == nil || n.Valid {
+		t.Errorf("expected error, got %v", n)
+	}
+}
+
+func TestNullBMGIDPtr(t *testing.T) {
+	if NullBMGIDFromPtr(nil).Valid || (NullBMGID{}).Ptr() != nil {
+		t.Errorf("expected null")
+	}
+	id := NewBMGID()
+	n := NullBMGIDFromPtr(&id)
+	if !n.Valid || *n.Ptr() != id || n.Ptr() == &n.BMGID {
+		t.Errorf("expected %s, got %v", id, n)
+	}
+}
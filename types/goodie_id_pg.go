@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/go-pg/pg/types"
+	"github.com/jackc/pgtype"
+)
+
+// go-pg handling
+
+var _ types.ValueAppender = BMGID{}
+
+// AppendValue will append BMGID to go-pg query in the BMGIDSQLFormat form. go-pg v6
+// has no value scanner interface and scans values via Scan, which understands
+// bigint, uuid, text and bytea columns
+func (id BMGID) AppendValue(b []byte, quote int) []byte {
+	if integer, ok := id.Int(); ok {
+		switch BMGIDSQLFormat {
+		case SQLLegacyInt:
+			return strconv.AppendUint(b, integer, 10)
+		case SQLText:
+			return types.AppendString(b, strconv.FormatUint(integer, 10), quote)
+		}
+	}
+	return types.AppendString(b, id.String(), quote)
+}
+
+// pgx handling
+
+var (
+	_ pgtype.TextEncoder   = BMGID{}
+	_ pgtype.TextDecoder   = &BMGID{}
+	_ pgtype.BinaryDecoder = &BMGID{}
+)
+
+// EncodeText will encode BMGID for pgx in the BMGIDSQLFormat form, the text format
+// lets PostgreSQL convert the value to the type of the column
+func (id BMGID) EncodeText(ci *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	value, err := id.Value()
+	if err != nil {
+		return nil, err
+	}
+	if integer, ok := value.(int64); ok {
+		return strconv.AppendInt(buf, integer, 10), nil
+	}
+	return append(buf, value.(string)...), nil
+}
+
+// DecodeText will decode BMGID from pgx text format of bigint, uuid, text or bytea column
+func (id *BMGID) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+	if src == nil {
+		*id = BMGID{}
+		return nil
+	}
+	return id.Scan(string(src))
+}
+
+// DecodeBinary will decode BMGID from pgx binary format, the column type is detected
+// by the length: 8 bytes of bigint, 16 bytes of uuid or bytea, text otherwise
+func (id *BMGID) DecodeBinary(ci *pgtype.ConnInfo, src []byte) error {
+	if src == nil {
+		*id = BMGID{}
+		return nil
+	}
+	if len(src) == 8 && !isDecimal(src) {
+		integer := int64(binary.BigEndian.Uint64(src))
+		if integer < 0 {
+			return fmt.Errorf("DecodeBinary: negative BMGID %d", integer)
+		}
+		*id = BMGIDFromInt(uint64(integer))
+		return nil
+	}
+	return id.Scan(src)
+}
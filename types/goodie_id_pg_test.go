@@ -0,0 +1,108 @@
+package types
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAppendValue(t *testing.T) {
+	defer func(format SQLFormat) { BMGIDSQLFormat = format }(BMGIDSQLFormat)
+
+	uuid4 := BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}
+	cases := []struct {
+		name     string
+		format   SQLFormat
+		id       BMGID
+		quote    int
+		expected string
+	}{
+		{"UUID4", SQLUUID, uuid4, 1, "'a1c87dd5-265a-499f-abf6-ad79008afa14'"},
+		{"UUID4 unquoted", SQLUUID, uuid4, 0, "a1c87dd5-265a-499f-abf6-ad79008afa14"},
+		{"integer id as UUID", SQLUUID, BMGIDFromInt(12345), 1, "'00000000-0000-a000-3930-000000000000'"},
+		{"UUID4 as integer", SQLLegacyInt, uuid4, 1, "'a1c87dd5-265a-499f-abf6-ad79008afa14'"},
+		{"integer id as integer", SQLLegacyInt, BMGIDFromInt(12345), 1, "12345"},
+		{"integer id as text", SQLText, BMGIDFromInt(12345), 1, "'12345'"},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			BMGIDSQLFormat = tst.format
+			if b := tst.id.AppendValue([]byte("id = "), tst.quote); string(b) != "id = "+tst.expected {
+				t.Errorf("expected 'id = %s', got '%s'", tst.expected, b)
+			}
+		})
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	defer func(format SQLFormat) { BMGIDSQLFormat = format }(BMGIDSQLFormat)
+
+	BMGIDSQLFormat = SQLUUID
+	if b, err := BMGIDFromInt(12345).EncodeText(nil, nil); err != nil || string(b) != "00000000-0000-a000-3930-000000000000" {
+		t.Errorf("expected UUID, got '%s' (%v)", b, err)
+	}
+	BMGIDSQLFormat = SQLLegacyInt
+	if b, err := BMGIDFromInt(12345).EncodeText(nil, nil); err != nil || string(b) != "12345" {
+		t.Errorf("expected integer, got '%s' (%v)", b, err)
+	}
+	if _, err := BMGIDFromInt(1<<63).EncodeText(nil, nil); err == nil {
+		t.Errorf("expected overflow error")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	legacy := "00000000-0000-a000-3930-000000000000"
+	uuid4 := "a1c87dd5-265a-499f-abf6-ad79008afa14"
+	raw, _ := uuid.MustParse(uuid4).MarshalBinary()
+	bigint := make([]byte, 8)
+	binary.BigEndian.PutUint64(bigint, 12345)
+	negative := make([]byte, 8)
+	binary.BigEndian.PutUint64(negative, 1<<63)
+
+	cases := []struct {
+		name     string
+		binary   bool
+		src      []byte
+		expected string
+		fail     bool
+	}{
+		{"text bigint", false, []byte("12345"), legacy, false},
+		{"text uuid", false, []byte(uuid4), uuid4, false},
+		{"text bytea", false, []byte(`\xa1c87dd5265a499fabf6ad79008afa14`), uuid4, false},
+		{"text null", false, nil, "00000000-0000-0000-0000-000000000000", false},
+		{"text invalid bytea", false, []byte(`\xa1c8`), "", true},
+		{"binary bigint", true, bigint, legacy, false},
+		{"binary negative bigint", true, negative, "", true},
+		{"binary uuid", true, raw, uuid4, false},
+		{"binary text", true, []byte("12345"), legacy, false},
+		{"binary 8 digits text", true, []byte("12345678"), BMGIDFromInt(12345678).String(), false},
+		{"binary null", true, nil, "00000000-0000-0000-0000-000000000000", false},
+		{"binary garbage", true, []byte("abc"), "", true},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			id := NewBMGID()
+			var err error
+			if tst.binary {
+				err = id.DecodeBinary(nil, tst.src)
+			} else {
+				err = id.DecodeText(nil, tst.src)
+			}
+			if tst.fail {
+				if err == nil {
+					t.Errorf("expected error, got '%s'", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id.String() != tst.expected {
+				t.Errorf("expected '%s', got '%s'", tst.expected, id)
+			}
+		})
+	}
+}
@@ -1,10 +1,16 @@
 package types
 
 import (
-	"encoding/binary"
+	"bytes"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/json"
 	"github.com/google/uuid"
+	"math"
 	"math/rand"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -46,6 +52,7 @@ func testInteger(t *testing.T) {
 }
 
 func BenchmarkUUIDMarshalJSON(b *testing.B) {
+	b.ReportAllocs()
 	ref, _ := uuid.NewRandom()
 	id := BMGID{UUID: ref}
 
@@ -55,6 +62,7 @@ func BenchmarkUUIDMarshalJSON(b *testing.B) {
 }
 
 func BenchmarkUUIDUnmarshalJSON(b *testing.B) {
+	b.ReportAllocs()
 	bs := []byte(`"6b568013-b949-486e-ae36-8146459d422a"`)
 	id := BMGID{}
 	for i := 0; i < b.N; i++ {
@@ -63,11 +71,8 @@ func BenchmarkUUIDUnmarshalJSON(b *testing.B) {
 }
 
 func BenchmarkIntegerMarshalJSON(b *testing.B) {
-	id := BMGID{}
-	bs := [16]byte{}
-	bs[6] = 0xa0
-	binary.LittleEndian.PutUint64(bs[8:16], uint64(1823671253762))
-	id.UUID = bs
+	b.ReportAllocs()
+	id := BMGIDFromInt(1823671253762)
 
 	for i := 0; i < b.N; i++ {
 		id.MarshalJSON()
@@ -75,6 +80,7 @@ func BenchmarkIntegerMarshalJSON(b *testing.B) {
 }
 
 func BenchmarkIntegerUnmarshalJSON(b *testing.B) {
+	b.ReportAllocs()
 	bs := []byte(`"12398329734564"`)
 	id := BMGID{}
 	for i := 0; i < b.N; i++ {
@@ -82,14 +88,258 @@ func BenchmarkIntegerUnmarshalJSON(b *testing.B) {
 	}
 }
 
+func BenchmarkMaxIntegerMarshalJSON(b *testing.B) {
+	b.ReportAllocs()
+	id := BMGIDFromInt(math.MaxUint64)
+	for i := 0; i < b.N; i++ {
+		id.MarshalJSON()
+	}
+}
+
+func BenchmarkNumberUnmarshalJSON(b *testing.B) {
+	b.ReportAllocs()
+	bs := []byte(`18446744073709551615`)
+	id := BMGID{}
+	for i := 0; i < b.N; i++ {
+		id.UnmarshalJSON(bs)
+	}
+}
+
+func BenchmarkNullUnmarshalJSON(b *testing.B) {
+	b.ReportAllocs()
+	bs := []byte(`null`)
+	id := BMGID{}
+	for i := 0; i < b.N; i++ {
+		id.UnmarshalJSON(bs)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		id       BMGID
+		expected string
+	}{
+		{"UUID4", BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}, `"a1c87dd5-265a-499f-abf6-ad79008afa14"`},
+		{"zero", BMGID{}, `"00000000-0000-0000-0000-000000000000"`},
+		{"integer id", BMGIDFromInt(12345), `"12345"`},
+		{"zero integer id", BMGIDFromInt(0), `"0"`},
+		{"max integer id", BMGIDFromInt(math.MaxUint64), `"18446744073709551615"`},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			bs, err := tst.id.MarshalJSON()
+			if err != nil || string(bs) != tst.expected {
+				t.Errorf("expected %s, got %s (%v)", tst.expected, bs, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		json     string
+		expected BMGID
+		fail     bool
+	}{
+		{"UUID", `"a1c87dd5-265a-499f-abf6-ad79008afa14"`, BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}, false},
+		{"braced UUID", `"{a1c87dd5-265a-499f-abf6-ad79008afa14}"`, BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}, false},
+		{"integer string", `"12345"`, BMGIDFromInt(12345), false},
+		{"max integer string", `"18446744073709551615"`, BMGIDFromInt(math.MaxUint64), false},
+		{"integer number", `12345`, BMGIDFromInt(12345), false},
+		{"zero number", `0`, BMGIDFromInt(0), false},
+		{"max integer number", `18446744073709551615`, BMGIDFromInt(math.MaxUint64), false},
+		{"null", `null`, BMGID{}, false},
+		{"overflow string", `"18446744073709551616"`, BMGID{}, true},
+		{"overflow number", `18446744073709551616`, BMGID{}, true},
+		{"negative", `-1`, BMGID{}, true},
+		{"fraction", `1.5`, BMGID{}, true},
+		{"exponent", `1e3`, BMGID{}, true},
+		{"leading zero number", `0123`, BMGID{}, true},
+		{"unquoted UUID", `a1c87dd5-265a-499f-abf6-ad79008afa14`, BMGID{}, true},
+		{"missing closing quote", `"12345`, BMGID{}, true},
+		{"missing opening quote", `12345"`, BMGID{}, true},
+		{"single quote", `"`, BMGID{}, true},
+		{"empty string", `""`, BMGID{}, true},
+		{"escaped", `"\u0031"`, BMGID{}, true},
+		{"spaces", `" 12345"`, BMGID{}, true},
+		{"bool", `true`, BMGID{}, true},
+		{"garbage", `"abc"`, BMGID{}, true},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			id := NewBMGID()
+			err := id.UnmarshalJSON([]byte(tst.json))
+			if tst.fail {
+				if _, ok := err.(*json.UnmarshalTypeError); !ok {
+					t.Errorf("expected UnmarshalTypeError, got %v", err)
+				}
+				return
+			}
+			if err != nil || id != tst.expected {
+				t.Errorf("expected '%s', got '%s' (%v)", tst.expected, id, err)
+			}
+		})
+	}
+
+	var e struct{ ID BMGID }
+	if err := json.Unmarshal([]byte(`{"ID": 12345}`), &e); err != nil || e.ID != BMGIDFromInt(12345) {
+		t.Errorf("expected 12345, got '%s' (%v)", e.ID, err)
+	}
+}
+
+func TestJSONAllocations(t *testing.T) {
+	uuid4 := BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}
+	legacy := BMGIDFromInt(math.MaxUint64)
+	if n := testing.AllocsPerRun(100, func() { uuid4.MarshalJSON() }); n > 1 {
+		t.Errorf("expected 1 allocation of UUID marshaling, got %v", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { legacy.MarshalJSON() }); n > 1 {
+		t.Errorf("expected 1 allocation of integer marshaling, got %v", n)
+	}
+
+	id := BMGID{}
+	for _, input := range []string{`"a1c87dd5-265a-499f-abf6-ad79008afa14"`, `"18446744073709551615"`, `12345`, `null`} {
+		bs := []byte(input)
+		if n := testing.AllocsPerRun(100, func() { id.UnmarshalJSON(bs) }); n > 0 {
+			t.Errorf("expected no allocations of %s unmarshaling, got %v", input, n)
+		}
+	}
+}
+
+func TestNewBMGID(t *testing.T) {
+	id := NewBMGID()
+	if id.Version() != 4 || id.Variant() != uuid.RFC4122 {
+		t.Errorf("expected random UUID, got '%s'", id)
+	}
+	if id == NewBMGID() {
+		t.Errorf("expected unique IDs")
+	}
+}
+
+func TestNewBMGIDv7(t *testing.T) {
+	now := time.Date(2020, 3, 4, 5, 6, 7, 8e6, time.UTC)
+	g := &BMGIDGenerator{Now: func() time.Time { return now }}
+	id := g.New()
+	if id.Version() != 7 || id.Variant() != uuid.RFC4122 {
+		t.Errorf("expected UUID v7, got '%s'", id)
+	}
+	if !strings.HasPrefix(id.String(), "0170a3ef-ce20-7") {
+		t.Errorf("expected timestamp prefix '0170a3ef-ce20-7', got '%s'", id)
+	}
+	if id.IsLegacyInt() {
+		t.Errorf("expected not legacy ID")
+	}
+
+	now = now.Add(time.Millisecond)
+	next := g.New()
+	if next.String() <= id.String() {
+		t.Errorf("expected '%s' to be after '%s'", next, id)
+	}
+	if NewBMGIDv7().Version() != 7 {
+		t.Errorf("expected UUID v7")
+	}
+}
+
+func TestBMGIDFromInt(t *testing.T) {
+	cases := []struct {
+		name    string
+		integer uint64
+		text    string
+	}{
+		{"zero", 0, "00000000-0000-a000-0000-000000000000"},
+		{"integer", 0xb8dd5147cf88789e, "00000000-0000-a000-9e78-88cf4751ddb8"},
+		{"max uint64", 1<<64 - 1, "00000000-0000-a000-ffff-ffffffffffff"},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			id := BMGIDFromInt(tst.integer)
+			if id.String() != tst.text {
+				t.Errorf("expected '%s', got '%s'", tst.text, id)
+			}
+			integer, ok := id.Int()
+			if !ok || integer != tst.integer || !id.IsLegacyInt() {
+				t.Errorf("expected legacy integer %d, got %d (%v)", tst.integer, integer, ok)
+			}
+		})
+	}
+}
+
+func TestBMGIDFromString(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		text   string
+		legacy bool
+		fail   bool
+	}{
+		{"UUID4", "a1c87dd5-265a-499f-abf6-ad79008afa14", "a1c87dd5-265a-499f-abf6-ad79008afa14", false, false},
+		{"legacy UUID", "00000000-0000-a000-9e78-88cf4751ddb8", "00000000-0000-a000-9e78-88cf4751ddb8", true, false},
+		{"integer", "13320892641698150558", "00000000-0000-a000-9e78-88cf4751ddb8", true, false},
+		{"nonstandard UUID version", "686874f7-54fd-967a-a7e2-5582e19d950f", "686874f7-54fd-967a-a7e2-5582e19d950f", false, false},
+		{"negative integer", "-1", "", false, true},
+		{"overflow", "18446744073709551616", "", false, true},
+		{"garbage", "abc", "", false, true},
+		{"empty", "", "", false, true},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			id, err := BMGIDFromString(tst.input)
+			if tst.fail {
+				if err == nil {
+					t.Errorf("expected error, got '%s'", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id.String() != tst.text || id.IsLegacyInt() != tst.legacy {
+				t.Errorf("expected '%s' (legacy %v), got '%s' (legacy %v)", tst.text, tst.legacy, id, id.IsLegacyInt())
+			}
+		})
+	}
+}
+
+func TestIsLegacyInt(t *testing.T) {
+	cases := []struct {
+		name   string
+		text   string
+		legacy bool
+	}{
+		{"legacy", "00000000-0000-a000-9e78-88cf4751ddb8", true},
+		{"version 0xa with random bits", "686874f7-54fd-a67a-a7e2-5582e19d950f", false},
+		{"version 0xa with low bits", "00000000-0000-a100-9e78-88cf4751ddb8", false},
+		{"UUID4", "a1c87dd5-265a-499f-abf6-ad79008afa14", false},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			id := BMGID{UUID: uuid.MustParse(tst.text)}
+			if id.IsLegacyInt() != tst.legacy {
+				t.Errorf("expected %v, got %v", tst.legacy, id.IsLegacyInt())
+			}
+			if _, ok := id.Int(); ok != tst.legacy {
+				t.Errorf("expected %v, got %v", tst.legacy, ok)
+			}
+		})
+	}
+}
+
 func TestMarshalText(t *testing.T) {
 	cases := []struct {
-		name string
-		text string
+		name     string
+		text     string
+		expected string
 	}{
-		{"UUID4", "a1c87dd5-265a-499f-abf6-ad79008afa14"},
-		{"integer id", "00000000-0000-a000-9e78-88cf4751ddb8"},
-		{"nonstandard UUID version", "686874f7-54fd-967a-a7e2-5582e19d950f"},
+		{"UUID4", "a1c87dd5-265a-499f-abf6-ad79008afa14", "a1c87dd5-265a-499f-abf6-ad79008afa14"},
+		{"integer id", "00000000-0000-a000-9e78-88cf4751ddb8", "13320892641698150558"},
+		{"nonstandard UUID version", "686874f7-54fd-967a-a7e2-5582e19d950f", "686874f7-54fd-967a-a7e2-5582e19d950f"},
 	}
 
 	for _, tst := range cases {
@@ -97,8 +347,8 @@ func TestMarshalText(t *testing.T) {
 			u, _ := uuid.Parse(tst.text)
 			id := BMGID{UUID: u}
 			bs, _ := id.MarshalText()
-			if tst.text != string(bs) {
-				t.Errorf("expecterd '%s', got '%s'", tst.text, string(bs))
+			if tst.expected != string(bs) {
+				t.Errorf("expecterd '%s', got '%s'", tst.expected, string(bs))
 			}
 		})
 	}
@@ -106,23 +356,72 @@ func TestMarshalText(t *testing.T) {
 
 func TestUnmarshalText(t *testing.T) {
 	cases := []struct {
-		name string
-		text string
+		name     string
+		text     string
+		expected string
 	}{
-		{"UUID4", "a1c87dd5-265a-499f-abf6-ad79008afa14"},
-		{"integer id", "00000000-0000-a000-9e78-88cf4751ddb8"},
-		{"nonstandard UUID version", "686874f7-54fd-967a-a7e2-5582e19d950f"},
+		{"UUID4", "a1c87dd5-265a-499f-abf6-ad79008afa14", "a1c87dd5-265a-499f-abf6-ad79008afa14"},
+		{"integer id", "00000000-0000-a000-9e78-88cf4751ddb8", "00000000-0000-a000-9e78-88cf4751ddb8"},
+		{"decimal integer id", "13320892641698150558", "00000000-0000-a000-9e78-88cf4751ddb8"},
+		{"nonstandard UUID version", "686874f7-54fd-967a-a7e2-5582e19d950f", "686874f7-54fd-967a-a7e2-5582e19d950f"},
 	}
 
 	for _, tst := range cases {
 		t.Run(tst.name, func(t *testing.T) {
 			id := BMGID{}
-			id.UnmarshalText([]byte(tst.text))
-			if tst.text != id.String() {
-				t.Errorf("expecterd '%s', got '%s'", tst.text, id.String())
+			if err := id.UnmarshalText([]byte(tst.text)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tst.expected != id.String() {
+				t.Errorf("expecterd '%s', got '%s'", tst.expected, id.String())
 			}
 		})
 	}
+
+	if err := (&BMGID{}).UnmarshalText([]byte("-1")); err == nil {
+		t.Errorf("expected error for negative integer")
+	}
+}
+
+func TestTextMapKeys(t *testing.T) {
+	ref := map[BMGID]int{BMGIDFromInt(12345): 1, NewBMGID(): 2}
+	bs, err := json.Marshal(ref)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(bs), `"12345":1`) {
+		t.Errorf("expected decimal legacy key, got %s", bs)
+	}
+
+	tst := map[BMGID]int{}
+	if err := json.Unmarshal(bs, &tst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ref, tst) {
+		t.Errorf("expected %v, got %v", ref, tst)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	for _, ref := range []BMGID{NewBMGID(), BMGIDFromInt(12345)} {
+		bs, err := ref.MarshalBinary()
+		if err != nil || len(bs) != 16 {
+			t.Fatalf("expected 16 bytes, got %v (%v)", bs, err)
+		}
+		tst := BMGID{}
+		if err := tst.UnmarshalBinary(bs); err != nil || tst != ref {
+			t.Errorf("expected '%s', got '%s' (%v)", ref, tst, err)
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(ref); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tst = BMGID{}
+		if err := gob.NewDecoder(&buf).Decode(&tst); err != nil || tst != ref {
+			t.Errorf("expected '%s', got '%s' (%v)", ref, tst, err)
+		}
+	}
 }
 
 func BenchmarkMarshalText(b *testing.B) {
@@ -165,6 +464,40 @@ func TestValue(t *testing.T) {
 	}
 }
 
+func TestValueFormats(t *testing.T) {
+	defer func(format SQLFormat) { BMGIDSQLFormat = format }(BMGIDSQLFormat)
+
+	uuid4 := BMGID{UUID: uuid.MustParse("a1c87dd5-265a-499f-abf6-ad79008afa14")}
+	cases := []struct {
+		name     string
+		format   SQLFormat
+		id       BMGID
+		expected driver.Value
+		fail     bool
+	}{
+		{"UUID4 as UUID", SQLUUID, uuid4, "a1c87dd5-265a-499f-abf6-ad79008afa14", false},
+		{"integer id as UUID", SQLUUID, BMGIDFromInt(12345), "00000000-0000-a000-3930-000000000000", false},
+		{"UUID4 as integer", SQLLegacyInt, uuid4, "a1c87dd5-265a-499f-abf6-ad79008afa14", false},
+		{"integer id as integer", SQLLegacyInt, BMGIDFromInt(12345), int64(12345), false},
+		{"integer id overflow", SQLLegacyInt, BMGIDFromInt(math.MaxInt64 + 1), nil, true},
+		{"UUID4 as text", SQLText, uuid4, "a1c87dd5-265a-499f-abf6-ad79008afa14", false},
+		{"integer id as text", SQLText, BMGIDFromInt(12345), "12345", false},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			BMGIDSQLFormat = tst.format
+			val, err := tst.id.Value()
+			if (err != nil) != tst.fail {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if val != tst.expected {
+				t.Errorf("expected '%v', got '%v'", tst.expected, val)
+			}
+		})
+	}
+}
+
 func TestScan(t *testing.T) {
 	cases := []struct {
 		name string
@@ -186,6 +519,49 @@ func TestScan(t *testing.T) {
 	}
 }
 
+func TestScanLegacyInt(t *testing.T) {
+	raw, _ := BMGIDFromInt(12345).MarshalBinary()
+	cases := []struct {
+		name     string
+		value    interface{}
+		expected string
+		fail     bool
+	}{
+		{"bigint", int64(12345), "00000000-0000-a000-3930-000000000000", false},
+		{"decimal string", "12345", "00000000-0000-a000-3930-000000000000", false},
+		{"decimal bytes", []byte("12345"), "00000000-0000-a000-3930-000000000000", false},
+		{"16 digits decimal bytes", []byte("1234567890123456"), BMGIDFromInt(1234567890123456).String(), false},
+		{"raw bytes", raw, "00000000-0000-a000-3930-000000000000", false},
+		{"UUID string", "a1c87dd5-265a-499f-abf6-ad79008afa14", "a1c87dd5-265a-499f-abf6-ad79008afa14", false},
+		{"null", nil, "00000000-0000-0000-0000-000000000000", false},
+		{"empty string", "", "00000000-0000-0000-0000-000000000000", false},
+		{"empty bytes", []byte{}, "00000000-0000-0000-0000-000000000000", false},
+		{"negative bigint", int64(-1), "", true},
+		{"garbage", "abc", "", true},
+		{"float", 1.5, "", true},
+	}
+
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			// a reused value must not keep the previous ID
+			id := NewBMGID()
+			err := id.Scan(tst.value)
+			if tst.fail {
+				if err == nil {
+					t.Errorf("expected error, got '%s'", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tst.expected != id.String() {
+				t.Errorf("expected '%s', got '%s'", tst.expected, id.String())
+			}
+		})
+	}
+}
+
 func BenchmarkValue(b *testing.B) {
 	u, _ := uuid.NewRandom()
 	id := BMGID{UUID: u}
@@ -0,0 +1,381 @@
+package types
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrIDNotMapped is returned if legacy integer ID has no UUID
+var ErrIDNotMapped = errors.New("legacy ID is not mapped")
+
+// IDMapper will translate legacy integer IDs into UUIDs during the migration,
+// ErrIDNotMapped is returned for unknown IDs
+type IDMapper interface {
+	Resolve(legacy uint64) (BMGID, error)
+}
+
+// BulkIDMapper is an IDMapper which translates many legacy IDs at once,
+// IDs without UUIDs are missed in the result
+type BulkIDMapper interface {
+	IDMapper
+	ResolveAll(legacy []uint64) (map[uint64]BMGID, error)
+}
+
+// Memory mapping
+
+// MemoryIDMapper keeps the mapping in memory, it's safe for concurrent use
+type MemoryIDMapper struct {
+	mu  sync.RWMutex
+	ids map[uint64]BMGID
+}
+
+// NewMemoryIDMapper will create in-memory mapper with a copy of the mapping
+func NewMemoryIDMapper(ids map[uint64]BMGID) *MemoryIDMapper {
+	m := &MemoryIDMapper{ids: make(map[uint64]BMGID, len(ids))}
+	for legacy, id := range ids {
+		m.ids[legacy] = id
+	}
+	return m
+}
+
+// Add will map legacy ID to UUID
+func (m *MemoryIDMapper) Add(legacy uint64, id BMGID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ids == nil {
+		m.ids = make(map[uint64]BMGID)
+	}
+	m.ids[legacy] = id
+}
+
+// Resolve will return UUID of legacy ID
+func (m *MemoryIDMapper) Resolve(legacy uint64) (BMGID, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	id, ok := m.ids[legacy]
+	if !ok {
+		return BMGID{}, notMapped(legacy)
+	}
+	return id, nil
+}
+
+// ResolveAll will return UUIDs of legacy IDs
+func (m *MemoryIDMapper) ResolveAll(legacy []uint64) (map[uint64]BMGID, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make(map[uint64]BMGID, len(legacy))
+	for _, integer := range legacy {
+		if id, ok := m.ids[integer]; ok {
+			ids[integer] = id
+		}
+	}
+	return ids, nil
+}
+
+// SQL mapping
+
+// Querier is a part of *sql.DB and *sql.Tx which is used by SQLIDMapper
+type Querier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// DefaultIDMapperBatchSize is a number of legacy IDs which SQLIDMapper queries at once by default
+const DefaultIDMapperBatchSize = 1000
+
+// SQLIDMapper reads the mapping from a table with legacy integer and UUID columns,
+// queries use PostgreSQL placeholders ($1), identifiers are quoted
+type SQLIDMapper struct {
+	DB           Querier
+	Table        string // a table name, may be qualified by a schema, e.g. "migration.user_ids"
+	LegacyColumn string // a bigint column of legacy IDs
+	UUIDColumn   string // a uuid column
+	BatchSize    int    // a number of IDs in a query of ResolveAll, DefaultIDMapperBatchSize if it's not set
+}
+
+// Resolve will query UUID of legacy ID
+func (m SQLIDMapper) Resolve(legacy uint64) (BMGID, error) {
+	arg, err := legacyArg(legacy)
+	if err != nil {
+		return BMGID{}, err
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1",
+		quoteIdentifier(m.UUIDColumn), quoteIdentifier(m.Table), quoteIdentifier(m.LegacyColumn))
+
+	var id BMGID
+	err = m.DB.QueryRow(query, arg).Scan(&id)
+	if err == sql.ErrNoRows {
+		return BMGID{}, notMapped(legacy)
+	}
+	return id, err
+}
+
+// ResolveAll will query UUIDs of legacy IDs by batches of BatchSize IDs, so the number of
+// bind parameters stays under the PostgreSQL limit
+func (m SQLIDMapper) ResolveAll(legacy []uint64) (map[uint64]BMGID, error) {
+	size := m.BatchSize
+	if size <= 0 {
+		size = DefaultIDMapperBatchSize
+	}
+	ids := make(map[uint64]BMGID, len(legacy))
+	for start := 0; start < len(legacy); start += size {
+		end := start + size
+		if end > len(legacy) {
+			end = len(legacy)
+		}
+		if err := m.resolveBatch(legacy[start:end], ids); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// resolveBatch will query UUIDs of legacy IDs by a single query and save them to ids
+func (m SQLIDMapper) resolveBatch(legacy []uint64, ids map[uint64]BMGID) error {
+	args := make([]interface{}, 0, len(legacy))
+	placeholders := make([]string, 0, len(legacy))
+	for _, integer := range legacy {
+		arg, err := legacyArg(integer)
+		if err != nil {
+			return err
+		}
+		args = append(args, arg)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+	query := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s IN (%s)",
+		quoteIdentifier(m.LegacyColumn), quoteIdentifier(m.UUIDColumn), quoteIdentifier(m.Table),
+		quoteIdentifier(m.LegacyColumn), strings.Join(placeholders, ", "))
+
+	rows, err := m.DB.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var integer int64
+		var id BMGID
+		if err := rows.Scan(&integer, &id); err != nil {
+			return err
+		}
+		ids[uint64(integer)] = id
+	}
+	return rows.Err()
+}
+
+// legacyArg will convert legacy ID into bigint argument
+func legacyArg(legacy uint64) (int64, error) {
+	if legacy > math.MaxInt64 {
+		return 0, fmt.Errorf("legacy ID %d overflows bigint", legacy)
+	}
+	return int64(legacy), nil
+}
+
+// quoteIdentifier will quote every part of a dotted SQL identifier
+func quoteIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+func notMapped(legacy uint64) error {
+	return fmt.Errorf("%w: %d", ErrIDNotMapped, legacy)
+}
+
+// Canonicalization
+
+// Canonicalize will translate legacy integer ID into its UUID, other IDs are returned as is
+func (id BMGID) Canonicalize(m IDMapper) (BMGID, error) {
+	legacy, ok := id.Int()
+	if !ok {
+		return id, nil
+	}
+	return m.Resolve(legacy)
+}
+
+// CanonicalizeAll will translate legacy integer IDs into UUIDs, the mapper is
+// queried once if it's a BulkIDMapper
+func CanonicalizeAll(ids []BMGID, m IDMapper) ([]BMGID, error) {
+	legacy := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if integer, ok := id.Int(); ok {
+			legacy = append(legacy, integer)
+		}
+	}
+	mapped, err := resolveAll(m, legacy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]BMGID, len(ids))
+	for i, id := range ids {
+		if integer, ok := id.Int(); ok {
+			id = mapped[integer]
+		}
+		result[i] = id
+	}
+	return result, nil
+}
+
+// CanonicalizeJSON will rewrite IDs of the JSON document into canonical UUID strings,
+// IDs are values (or arrays of values) of the fields with given names at any depth and
+// may be UUIDs, legacy integers as strings or numbers and compact presentations.
+// The document is re-encoded, so keys of objects are sorted
+func CanonicalizeJSON(doc []byte, m IDMapper, fields ...string) ([]byte, error) {
+	docs, err := CanonicalizeJSONDocuments([][]byte{doc}, m, fields...)
+	if err != nil {
+		return nil, err
+	}
+	return docs[0], nil
+}
+
+// CanonicalizeJSONDocuments will rewrite IDs of JSON documents like CanonicalizeJSON,
+// legacy IDs of all documents are resolved at once
+func CanonicalizeJSONDocuments(docs [][]byte, m IDMapper, fields ...string) ([][]byte, error) {
+	names := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		names[field] = true
+	}
+
+	values := make([]interface{}, len(docs))
+	legacy := make([]uint64, 0)
+	for i, doc := range docs {
+		decoder := json.NewDecoder(bytes.NewReader(doc))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values[i]); err != nil {
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+		err := walkJSONIDs(values[i], names, func(id BMGID) BMGID {
+			if integer, ok := id.Int(); ok {
+				legacy = append(legacy, integer)
+			}
+			return id
+		})
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+	}
+
+	mapped, err := resolveAll(m, legacy)
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]byte, len(docs))
+	for i := range values {
+		_ = walkJSONIDs(values[i], names, func(id BMGID) BMGID {
+			if integer, ok := id.Int(); ok {
+				return mapped[integer]
+			}
+			return id
+		})
+		if result[i], err = json.Marshal(values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// walkJSONIDs will replace IDs of the decoded JSON value by the function
+func walkJSONIDs(value interface{}, fields map[string]bool, replace func(BMGID) BMGID) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if !fields[key] {
+				if err := walkJSONIDs(item, fields, replace); err != nil {
+					return err
+				}
+				continue
+			}
+			replaced, err := replaceJSONIDs(item, replace)
+			if err != nil {
+				return fmt.Errorf("field %q: %v", key, err)
+			}
+			v[key] = replaced
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := walkJSONIDs(item, fields, replace); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replaceJSONIDs will replace the ID or an array of IDs, nulls are kept
+func replaceJSONIDs(value interface{}, replace func(BMGID) BMGID) (interface{}, error) {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		for i, item := range v {
+			replaced, err := replaceJSONIDs(item, replace)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = replaced
+		}
+		return v, nil
+	case string:
+		text = v
+	case json.Number:
+		text = v.String()
+	default:
+		return nil, fmt.Errorf("unexpected ID %v", value)
+	}
+	id, err := BMGIDFromString(text)
+	if err != nil {
+		return nil, err
+	}
+	return replace(id).String(), nil
+}
+
+// resolveAll will translate legacy IDs by the mapper, ErrIDNotMapped is returned
+// if some IDs are not mapped
+func resolveAll(m IDMapper, legacy []uint64) (map[uint64]BMGID, error) {
+	if len(legacy) == 0 {
+		return map[uint64]BMGID{}, nil
+	}
+	unique := make([]uint64, 0, len(legacy))
+	seen := make(map[uint64]bool, len(legacy))
+	for _, integer := range legacy {
+		if !seen[integer] {
+			seen[integer] = true
+			unique = append(unique, integer)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool { return unique[i] < unique[j] })
+
+	if bulk, ok := m.(BulkIDMapper); ok {
+		mapped, err := bulk.ResolveAll(unique)
+		if err != nil {
+			return nil, err
+		}
+		for _, integer := range unique {
+			if _, ok := mapped[integer]; !ok {
+				return nil, notMapped(integer)
+			}
+		}
+		return mapped, nil
+	}
+
+	mapped := make(map[uint64]BMGID, len(unique))
+	for _, integer := range unique {
+		id, err := m.Resolve(integer)
+		if err != nil {
+			return nil, err
+		}
+		mapped[integer] = id
+	}
+	return mapped, nil
+}
@@ -0,0 +1,295 @@
+package types
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeMappingDriver serves queries of SQLIDMapper from the in-memory table
+type fakeMappingDriver struct {
+	table   map[int64]string
+	queries []string
+}
+
+func (d *fakeMappingDriver) Connect(context.Context) (driver.Conn, error) {
+	return fakeMappingConn{d}, nil
+}
+func (d *fakeMappingDriver) Driver() driver.Driver            { return d }
+func (d *fakeMappingDriver) Open(string) (driver.Conn, error) { return fakeMappingConn{d}, nil }
+
+type fakeMappingConn struct{ d *fakeMappingDriver }
+
+func (c fakeMappingConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.queries = append(c.d.queries, query)
+	return fakeMappingStmt{c.d, query}, nil
+}
+func (c fakeMappingConn) Close() error              { return nil }
+func (c fakeMappingConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeMappingStmt struct {
+	d     *fakeMappingDriver
+	query string
+}
+
+func (s fakeMappingStmt) Close() error  { return nil }
+func (s fakeMappingStmt) NumInput() int { return -1 }
+func (s fakeMappingStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s fakeMappingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	bulk := strings.Contains(s.query, " IN ")
+	rows := &fakeMappingRows{bulk: bulk}
+	for _, arg := range args {
+		if id, ok := s.d.table[arg.(int64)]; ok {
+			rows.rows = append(rows.rows, []driver.Value{arg, id})
+		}
+	}
+	return rows, nil
+}
+
+type fakeMappingRows struct {
+	bulk bool
+	rows [][]driver.Value
+}
+
+func (r *fakeMappingRows) Columns() []string {
+	if r.bulk {
+		return []string{"legacy_id", "uuid"}
+	}
+	return []string{"uuid"}
+}
+func (r *fakeMappingRows) Close() error { return nil }
+func (r *fakeMappingRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	if r.bulk {
+		copy(dest, r.rows[0])
+	} else {
+		dest[0] = r.rows[0][1]
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// countingMapper is not a BulkIDMapper, it counts Resolve calls
+type countingMapper struct {
+	ids   map[uint64]BMGID
+	calls int
+}
+
+func (m *countingMapper) Resolve(legacy uint64) (BMGID, error) {
+	m.calls++
+	if id, ok := m.ids[legacy]; ok {
+		return id, nil
+	}
+	return BMGID{}, notMapped(legacy)
+}
+
+const (
+	mappedUUID1 = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	mappedUUID2 = "6ba7b811-9dad-11d1-80b4-00c04fd430c8"
+)
+
+func mustBMGID(t *testing.T, s string) BMGID {
+	t.Helper()
+	id, err := BMGIDFromString(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return id
+}
+
+func TestMemoryIDMapper(t *testing.T) {
+	id := mustBMGID(t, mappedUUID1)
+	m := NewMemoryIDMapper(map[uint64]BMGID{1: id})
+	m.Add(2, id)
+
+	cases := []struct {
+		name   string
+		legacy uint64
+		err    error
+	}{
+		{"initial", 1, nil},
+		{"added", 2, nil},
+		{"missing", 3, ErrIDNotMapped},
+	}
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			got, err := m.Resolve(tst.legacy)
+			if !errors.Is(err, tst.err) {
+				t.Fatalf("expected error %v, got %v", tst.err, err)
+			}
+			if err == nil && got != id {
+				t.Errorf("expected %s, got %s", id, got)
+			}
+		})
+	}
+
+	var zero MemoryIDMapper
+	zero.Add(1, id)
+	if got, err := zero.Resolve(1); err != nil || got != id {
+		t.Errorf("expected %s of zero mapper, got %s (%v)", id, got, err)
+	}
+}
+
+func TestSQLIDMapper(t *testing.T) {
+	d := &fakeMappingDriver{table: map[int64]string{1: mappedUUID1, 2: mappedUUID2}}
+	db := sql.OpenDB(d)
+	defer db.Close()
+	m := SQLIDMapper{DB: db, Table: "migration.user_ids", LegacyColumn: "legacy_id", UUIDColumn: `"uuid"`}
+
+	id, err := m.Resolve(1)
+	if err != nil || id.String() != mappedUUID1 {
+		t.Errorf("expected %s, got %s (%v)", mappedUUID1, id, err)
+	}
+	expected := `SELECT """uuid""" FROM "migration"."user_ids" WHERE "legacy_id" = $1`
+	if query := d.queries[len(d.queries)-1]; query != expected {
+		t.Errorf("expected query %s, got %s", expected, query)
+	}
+	if _, err := m.Resolve(3); !errors.Is(err, ErrIDNotMapped) {
+		t.Errorf("expected ErrIDNotMapped, got %v", err)
+	}
+	if _, err := m.Resolve(1 << 63); err == nil {
+		t.Errorf("expected overflow error")
+	}
+
+	ids, err := m.ResolveAll([]uint64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 2 || ids[1].String() != mappedUUID1 || ids[2].String() != mappedUUID2 {
+		t.Errorf("unexpected mapping %v", ids)
+	}
+	expected = `SELECT "legacy_id", """uuid""" FROM "migration"."user_ids" WHERE "legacy_id" IN ($1, $2, $3)`
+	if query := d.queries[len(d.queries)-1]; query != expected {
+		t.Errorf("expected query %s, got %s", expected, query)
+	}
+
+	d.queries = nil
+	m.BatchSize = 2
+	ids, err = m.ResolveAll([]uint64{1, 2, 3})
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("unexpected mapping %v (%v)", ids, err)
+	}
+	expected = `SELECT "legacy_id", """uuid""" FROM "migration"."user_ids" WHERE "legacy_id" IN ($1)`
+	if len(d.queries) != 2 || d.queries[1] != expected {
+		t.Errorf("expected 2 batches, got %v", d.queries)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	mapped := mustBMGID(t, mappedUUID1)
+	m := NewMemoryIDMapper(map[uint64]BMGID{1: mapped})
+	other := NewBMGID()
+
+	cases := []struct {
+		name     string
+		id       BMGID
+		expected BMGID
+		err      error
+	}{
+		{"legacy", BMGIDFromInt(1), mapped, nil},
+		{"uuid", other, other, nil},
+		{"zero", BMGID{}, BMGID{}, nil},
+		{"not mapped", BMGIDFromInt(2), BMGID{}, ErrIDNotMapped},
+	}
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			got, err := tst.id.Canonicalize(m)
+			if !errors.Is(err, tst.err) {
+				t.Fatalf("expected error %v, got %v", tst.err, err)
+			}
+			if got != tst.expected {
+				t.Errorf("expected %s, got %s", tst.expected, got)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeAll(t *testing.T) {
+	mapped := mustBMGID(t, mappedUUID1)
+	other := NewBMGID()
+	m := &countingMapper{ids: map[uint64]BMGID{1: mapped}}
+
+	ids, err := CanonicalizeAll([]BMGID{BMGIDFromInt(1), other, BMGIDFromInt(1)}, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids[0] != mapped || ids[1] != other || ids[2] != mapped {
+		t.Errorf("unexpected IDs %v", ids)
+	}
+	if m.calls != 1 {
+		t.Errorf("expected a single call of the mapper, got %d", m.calls)
+	}
+	if _, err := CanonicalizeAll([]BMGID{BMGIDFromInt(2)}, m); !errors.Is(err, ErrIDNotMapped) {
+		t.Errorf("expected ErrIDNotMapped, got %v", err)
+	}
+	if _, err := CanonicalizeAll([]BMGID{BMGIDFromInt(2)}, NewMemoryIDMapper(nil)); !errors.Is(err, ErrIDNotMapped) {
+		t.Errorf("expected ErrIDNotMapped of bulk mapper, got %v", err)
+	}
+}
+
+func TestCanonicalizeJSON(t *testing.T) {
+	m := NewMemoryIDMapper(map[uint64]BMGID{
+		1: mustBMGID(t, mappedUUID1),
+		2: mustBMGID(t, mappedUUID2),
+	})
+	compact := mustBMGID(t, mappedUUID2).Encode(EncodingBase62)
+
+	cases := []struct {
+		name     string
+		doc      string
+		expected string
+		err      bool
+	}{
+		{"integer", `{"id":1,"count":1}`, `{"count":1,"id":"` + mappedUUID1 + `"}`, false},
+		{"string", `{"id":"2"}`, `{"id":"` + mappedUUID2 + `"}`, false},
+		{"uuid", `{"id":"` + mappedUUID1 + `"}`, `{"id":"` + mappedUUID1 + `"}`, false},
+		{"compact", `{"id":"` + compact + `"}`, `{"id":"` + mappedUUID2 + `"}`, false},
+		{"null", `{"id":null}`, `{"id":null}`, false},
+		{"nested", `{"items":[{"owner_id":1},{"owner_id":"2"}]}`,
+			`{"items":[{"owner_id":"` + mappedUUID1 + `"},{"owner_id":"` + mappedUUID2 + `"}]}`, false},
+		{"array", `{"id":[1,2]}`, `{"id":["` + mappedUUID1 + `","` + mappedUUID2 + `"]}`, false},
+		{"not mapped", `{"id":3}`, "", true},
+		{"invalid", `{"id":"abc"}`, "", true},
+		{"object", `{"id":{}}`, "", true},
+		{"malformed", `{"id":`, "", true},
+	}
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			got, err := CanonicalizeJSON([]byte(tst.doc), m, "id", "owner_id")
+			if tst.err {
+				if err == nil {
+					t.Errorf("expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tst.expected {
+				t.Errorf("expected %s, got %s", tst.expected, got)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeJSONDocuments(t *testing.T) {
+	m := &countingMapper{ids: map[uint64]BMGID{1: mustBMGID(t, mappedUUID1)}}
+	docs, err := CanonicalizeJSONDocuments([][]byte{[]byte(`{"id":1}`), []byte(`[{"id":"1"}]`)}, m, "id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(docs[0]) != `{"id":"`+mappedUUID1+`"}` || string(docs[1]) != `[{"id":"`+mappedUUID1+`"}]` {
+		t.Errorf("unexpected documents %s", docs)
+	}
+	if m.calls != 1 {
+		t.Errorf("expected a single call of the mapper, got %d", m.calls)
+	}
+}
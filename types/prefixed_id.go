@@ -0,0 +1,111 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Prefix describes an entity of prefixed IDs, eg:
+//
+//	type UserPrefix struct{}
+//
+//	func (UserPrefix) Prefix() string { return "usr" }
+//
+//	type UserID = types.PrefixedID[UserPrefix]
+type Prefix interface {
+	Prefix() string
+}
+
+// prefixSeparator separates a prefix and BMGID, e.g. "usr_12345"
+const prefixSeparator = "_"
+
+// PrefixedID is a BMGID of a certain entity: IDs of different entities have different
+// types, JSON and text presentations carry the prefix (e.g. "usr_12345"), which is
+// validated on unmarshaling, SQL values are stored without the prefix
+type PrefixedID[P Prefix] struct {
+	BMGID
+}
+
+// PrefixedIDFrom will create prefixed ID of BMGID
+func PrefixedIDFrom[P Prefix](id BMGID) PrefixedID[P] {
+	return PrefixedID[P]{BMGID: id}
+}
+
+// ParsePrefixedID will parse prefixed presentation of ID, the prefix is required
+func ParsePrefixedID[P Prefix](s string) (PrefixedID[P], error) {
+	var id PrefixedID[P]
+	prefix := id.Prefix() + prefixSeparator
+	if !strings.HasPrefix(s, prefix) {
+		return id, fmt.Errorf("invalid ID %q: expected %q prefix", s, prefix)
+	}
+	parsed, err := BMGIDFromString(s[len(prefix):])
+	if err != nil {
+		return id, err
+	}
+	id.BMGID = parsed
+	return id, nil
+}
+
+// Prefix will return the prefix of the entity without the separator
+func (id PrefixedID[P]) Prefix() string {
+	var p P
+	return p.Prefix()
+}
+
+// String will return prefixed presentation of ID
+func (id PrefixedID[P]) String() string {
+	return string(id.appendPrefixed(make([]byte, 0, 40)))
+}
+
+// appendPrefixed will append prefixed presentation of ID
+func (id PrefixedID[P]) appendPrefixed(b []byte) []byte {
+	b = append(b, id.Prefix()...)
+	b = append(b, prefixSeparator...)
+	return id.BMGID.appendText(b)
+}
+
+// JSON handling
+
+// MarshalJSON will marshal prefixed ID into JSON string
+func (id PrefixedID[P]) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 42)
+	b = append(b, '"')
+	b = id.appendPrefixed(b)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON will unmarshal JSON string of prefixed ID, null is unmarshaled into zero ID
+func (id *PrefixedID[P]) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*id = PrefixedID[P]{}
+		return nil
+	}
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return &json.UnmarshalTypeError{
+			Value:  string(b),
+			Type:   reflect.TypeOf(id).Elem(),
+			Struct: "PrefixedID",
+			Field:  "uuid",
+		}
+	}
+	return id.UnmarshalText(b[1 : len(b)-1])
+}
+
+// Text handling
+
+// MarshalText will marshal prefixed ID into text
+func (id PrefixedID[P]) MarshalText() ([]byte, error) {
+	return id.appendPrefixed(make([]byte, 0, 40)), nil
+}
+
+// UnmarshalText will unmarshal prefixed text presentation of ID
+func (id *PrefixedID[P]) UnmarshalText(b []byte) error {
+	parsed, err := ParsePrefixedID[P](string(b))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type testUserPrefix struct{}
+
+func (testUserPrefix) Prefix() string { return "usr" }
+
+type testOrderPrefix struct{}
+
+func (testOrderPrefix) Prefix() string { return "ord" }
+
+type testUserID = PrefixedID[testUserPrefix]
+
+type testOrderID = PrefixedID[testOrderPrefix]
+
+func TestPrefixedIDJSON(t *testing.T) {
+	type order struct {
+		ID     testOrderID  `json:"id"`
+		UserID *testUserID  `json:"user_id"`
+		Users  []testUserID `json:"users"`
+	}
+	userID := PrefixedIDFrom[testUserPrefix](BMGIDFromInt(12345))
+	ref := order{
+		ID:     PrefixedIDFrom[testOrderPrefix](BMGIDFromInt(1)),
+		UserID: &userID,
+		Users:  []testUserID{userID},
+	}
+
+	bs, err := json.Marshal(ref)
+	if err != nil || string(bs) != `{"id":"ord_1","user_id":"usr_12345","users":["usr_12345"]}` {
+		t.Fatalf("unexpected JSON %s (%v)", bs, err)
+	}
+	var tst order
+	if err := json.Unmarshal(bs, &tst); err != nil || tst.ID != ref.ID || *tst.UserID != userID || tst.Users[0] != userID {
+		t.Errorf("expected %v, got %v (%v)", ref, tst, err)
+	}
+
+	cases := []struct {
+		name string
+		json string
+	}{
+		{"wrong prefix", `{"id":"usr_1"}`},
+		{"missing prefix", `{"id":"1"}`},
+		{"missing separator", `{"id":"ord1"}`},
+		{"invalid ID", `{"id":"ord_abc"}`},
+		{"number", `{"id":1}`},
+	}
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			var o order
+			if err := json.Unmarshal([]byte(tst.json), &o); err == nil {
+				t.Errorf("expected error, got %v", o)
+			}
+		})
+	}
+
+	var o order
+	if err := json.Unmarshal([]byte(`{"id":null,"user_id":null}`), &o); err != nil || o.ID != (testOrderID{}) || o.UserID != nil {
+		t.Errorf("expected zero IDs, got %v (%v)", o, err)
+	}
+}
+
+func TestPrefixedIDText(t *testing.T) {
+	uuid4 := NewBMGID()
+	id := PrefixedIDFrom[testUserPrefix](uuid4)
+	if id.String() != "usr_"+uuid4.String() || id.Prefix() != "usr" {
+		t.Errorf("expected 'usr_%s', got '%s'", uuid4, id)
+	}
+
+	bs, _ := id.MarshalText()
+	var tst testUserID
+	if err := tst.UnmarshalText(bs); err != nil || tst != id {
+		t.Errorf("expected '%s', got '%s' (%v)", id, tst, err)
+	}
+	if err := tst.UnmarshalText([]byte(uuid4.String())); err == nil {
+		t.Errorf("expected error for unprefixed ID")
+	}
+
+	m := map[testUserID]int{id: 1}
+	bs, _ = json.Marshal(m)
+	if string(bs) != `{"usr_`+uuid4.String()+`":1}` {
+		t.Errorf("expected prefixed map key, got %s", bs)
+	}
+
+	parsed, err := ParsePrefixedID[testUserPrefix]("usr_" + uuid4.Encode(EncodingBase62))
+	if err != nil || parsed != id {
+		t.Errorf("expected '%s', got '%s' (%v)", id, parsed, err)
+	}
+}
+
+func TestPrefixedIDSQL(t *testing.T) {
+	id := PrefixedIDFrom[testUserPrefix](BMGIDFromInt(12345))
+	if val, _ := id.Value(); val != "00000000-0000-a000-3930-000000000000" {
+		t.Errorf("expected unprefixed value, got %v", val)
+	}
+	var tst testUserID
+	if err := tst.Scan(int64(12345)); err != nil || tst != id {
+		t.Errorf("expected '%s', got '%s' (%v)", id, tst, err)
+	}
+	if b := id.AppendValue(nil, 1); string(b) != "'00000000-0000-a000-3930-000000000000'" {
+		t.Errorf("expected unprefixed value, got %s", b)
+	}
+}